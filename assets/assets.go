@@ -0,0 +1,90 @@
+// Package assets holds the default icons and sounds, embedded in the binary.
+//
+// Files in an override directory replace the defaults one by one: a file at
+// the same relative path (e.g. sounds/cancelled.wav) is used instead of the
+// embedded one, and everything else keeps the default.
+package assets
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/d-mozulyov/vox/internal/platform"
+)
+
+//go:embed icons/idle* icons/recording* icons/transcribing* icons/error* sounds/*.wav
+var defaults embed.FS
+
+// Default returns the embedded default assets
+func Default() fs.FS {
+	return defaults
+}
+
+// New returns the default assets with the files found in overrideDir replacing them
+// An empty overrideDir returns the defaults
+func New(overrideDir string) fs.FS {
+	if overrideDir == "" {
+		return defaults
+	}
+	return overlay{override: os.DirFS(overrideDir), base: defaults}
+}
+
+// overlay opens files from override, falling back to base
+type overlay struct {
+	override fs.FS
+	base     fs.FS
+}
+
+// Open opens the overriding file if there is one, otherwise the default
+func (o overlay) Open(name string) (fs.File, error) {
+	file, err := o.override.Open(name)
+	if err == nil {
+		if info, statErr := file.Stat(); statErr == nil && !info.IsDir() {
+			return file, nil
+		}
+		// Directories come from the defaults, so a partial override lists the default files
+		file.Close()
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		platform.GetLogger().Warn("Failed to open asset override %s, using the default: %v", name, err)
+	}
+	return o.base.Open(name)
+}
+
+// Export writes the default assets into dir, to be customized as overrides
+// Existing files are kept unless overwrite is set. Returns the paths of the written files
+func Export(dir string, overwrite bool) ([]string, error) {
+	var written []string
+	err := fs.WalkDir(defaults, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if !overwrite {
+			if _, err := os.Stat(path); err == nil {
+				return nil
+			}
+		}
+
+		data, err := fs.ReadFile(defaults, name)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return err
+		}
+		written = append(written, path)
+		return nil
+	})
+	if err != nil {
+		return written, fmt.Errorf("failed to export assets: %w", err)
+	}
+	return written, nil
+}
@@ -0,0 +1,81 @@
+package assets
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestOverride tests that override files replace the defaults one by one
+func TestOverride(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "sounds"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sounds", "cancelled.wav"), []byte("custom"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	assets := New(dir)
+	data, err := fs.ReadFile(assets, "sounds/cancelled.wav")
+	if err != nil || string(data) != "custom" {
+		t.Errorf("Expected the override, got %q, %v", data, err)
+	}
+
+	data, err = fs.ReadFile(assets, "sounds/error.wav")
+	if err != nil || !bytes.HasPrefix(data, []byte("RIFF")) {
+		t.Errorf("Expected the default sound, got %d bytes, %v", len(data), err)
+	}
+
+	// Sub-filesystems see the same files
+	icons, err := fs.Sub(assets, "icons")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.ReadFile(icons, "idle_24.png"); err != nil {
+		t.Errorf("Expected the default icon: %v", err)
+	}
+
+	if _, err := fs.ReadFile(New(""), "icons/idle.ico"); err != nil {
+		t.Errorf("Expected the default icon without overrides: %v", err)
+	}
+}
+
+// TestExport tests that the defaults are written without replacing customized files
+func TestExport(t *testing.T) {
+	dir := t.TempDir()
+	custom := filepath.Join(dir, "sounds", "cancelled.wav")
+	if err := os.MkdirAll(filepath.Dir(custom), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(custom, []byte("custom"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	written, err := Export(dir, false)
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if len(written) == 0 {
+		t.Fatal("Expected files to be written")
+	}
+	if data, _ := os.ReadFile(custom); string(data) != "custom" {
+		t.Error("Expected the customized file to be kept")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "icons", "idle_24.png")); err != nil {
+		t.Errorf("Expected the default icon to be exported: %v", err)
+	}
+
+	again, err := Export(dir, true)
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if len(again) != len(written)+1 {
+		t.Errorf("Expected all %d files to be overwritten, got %d", len(written)+1, len(again))
+	}
+	if data, _ := os.ReadFile(custom); string(data) == "custom" {
+		t.Error("Expected the customized file to be overwritten")
+	}
+}
@@ -16,7 +16,8 @@ def create_ico_files():
 
     print("Creating Windows ICO files from PNG sources...")
 
-    for state in ['idle', 'recording']:
+    states = ['idle', 'recording', 'transcribing', 'error'] + [f'recording_lvl{level}' for level in range(4)]
+    for state in states:
         # Load all available PNG sizes
         images = []
         missing_sizes = []
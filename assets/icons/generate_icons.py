@@ -2,7 +2,10 @@
 from PIL import Image, ImageDraw
 import os, subprocess
 
-def create_microphone_icon(size, color, is_recording=False):
+# Number of input level icons (recording_lvl0 .. recording_lvl3)
+LEVELS = 4
+
+def create_microphone_icon(size, color, is_recording=False, level=None, indicator=(255,0,0,255)):
     img = Image.new('RGBA', size, (0, 0, 0, 0))
     draw = ImageDraw.Draw(img)
     width, height = size
@@ -11,7 +14,15 @@ def create_microphone_icon(size, color, is_recording=False):
     body_w = width * 0.6
     body_h = height * 0.65
     body_x = (width - body_w) / 2
-    draw.rectangle([body_x, 0, body_x + body_w, body_h], fill=color)
+    if level is None:
+        draw.rectangle([body_x, 0, body_x + body_w, body_h], fill=color)
+    else:
+        # Level icons: dimmed body filled from the bottom by level/(LEVELS-1)
+        dim = color[:3] + (110,)
+        draw.rectangle([body_x, 0, body_x + body_w, body_h], fill=dim)
+        if level > 0:
+            fill_y = body_h * (1 - level / (LEVELS - 1))
+            draw.rectangle([body_x, fill_y, body_x + body_w, body_h], fill=color)
 
     # Stand (vertical rectangle)
     stand_w = max(3, width // 6)
@@ -26,10 +37,10 @@ def create_microphone_icon(size, color, is_recording=False):
     base_y = height - base_h
     draw.rectangle([base_x, base_y, base_x + base_w, height], fill=color)
 
-    # Red indicator
+    # Indicator dot (red while recording or after an error, orange while transcribing)
     if is_recording:
         ind_size = max(4, width // 4)
-        draw.ellipse([width-ind_size-2, 2, width-2, 2+ind_size], fill=(255,0,0,255))
+        draw.ellipse([width-ind_size-2, 2, width-2, 2+ind_size], fill=indicator)
 
     return img
 
@@ -38,6 +49,7 @@ def main():
     script_dir = os.path.dirname(os.path.abspath(__file__))
     GRAY = (128, 128, 128, 255)
     PURPLE = (138, 43, 226, 255)
+    ORANGE = (255, 165, 0, 255)
     sizes = [16, 22, 24, 32, 44, 48]
 
     print("Generating PNG icons...")
@@ -46,6 +58,13 @@ def main():
             os.path.join(script_dir, f'idle_{size}.png'))
         create_microphone_icon((size, size), PURPLE, True).save(
             os.path.join(script_dir, f'recording_{size}.png'))
+        for level in range(LEVELS):
+            create_microphone_icon((size, size), PURPLE, True, level).save(
+                os.path.join(script_dir, f'recording_lvl{level}_{size}.png'))
+        create_microphone_icon((size, size), PURPLE, True, indicator=ORANGE).save(
+            os.path.join(script_dir, f'transcribing_{size}.png'))
+        create_microphone_icon((size, size), GRAY, True).save(
+            os.path.join(script_dir, f'error_{size}.png'))
         print(f"  {size}x{size}")
 
     print("\nCalling convert_to_ico.py...")
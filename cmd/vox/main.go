@@ -1,15 +1,35 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/d-mozulyov/vox/assets"
+	"github.com/d-mozulyov/vox/internal/audio"
+	"github.com/d-mozulyov/vox/internal/dsp"
 	"github.com/d-mozulyov/vox/internal/hotkey"
 	"github.com/d-mozulyov/vox/internal/indicator"
+	"github.com/d-mozulyov/vox/internal/inserter"
+	"github.com/d-mozulyov/vox/internal/journal"
 	"github.com/d-mozulyov/vox/internal/platform"
+	"github.com/d-mozulyov/vox/internal/queue"
+	"github.com/d-mozulyov/vox/internal/recorder"
+	"github.com/d-mozulyov/vox/internal/recordings"
+	"github.com/d-mozulyov/vox/internal/session"
 	"github.com/d-mozulyov/vox/internal/state"
+	"github.com/d-mozulyov/vox/internal/support"
+	"github.com/d-mozulyov/vox/internal/transcription"
 	"github.com/d-mozulyov/vox/internal/tray"
+	"github.com/d-mozulyov/vox/internal/usage"
+	"github.com/d-mozulyov/vox/pkg/config"
 )
 
 // Version is set during build via -ldflags
@@ -26,6 +46,36 @@ func main() {
 			fmt.Println(Version)
 		case "help":
 			printHelp()
+		case "recover":
+			if err := runRecover(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		case "usage":
+			if err := runUsage(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		case "assets":
+			if len(os.Args) < 3 || os.Args[2] != "export" {
+				fmt.Println("Unknown assets command")
+				printHelp()
+				os.Exit(1)
+			}
+			if err := runAssetsExport(os.Args[3:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		case "backend":
+			if len(os.Args) < 3 || os.Args[2] != "test" {
+				fmt.Println("Unknown backend command")
+				printHelp()
+				os.Exit(1)
+			}
+			if err := runBackendTest(os.Args[3:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
 		default:
 			fmt.Printf("Unknown command: %s\n", os.Args[1])
 			printHelp()
@@ -51,33 +101,597 @@ func main() {
 
 	// Start main application
 	fmt.Println("Starting Vox...")
-	if err := run(); err != nil {
+	if err := run(logFilePath); err != nil {
 		logger.Fatal("Application error: %v", err)
 	}
 }
 
 // run initializes and runs the application
 // Integration flow:
+// 0. Load configuration (~/.vox/config.json)
 // 1. Initialize State Machine (manages application state)
-// 2. Initialize Hotkey Manager (registers Alt+Shift+V)
-// 3. Initialize Indicator Manager (coordinates visual + audio feedback)
-// 4. Initialize Tray Manager (system tray icon and menu)
-// 5. In onReady callback (when tray is ready):
+// 2. Initialize Recorder (microphone capture, DSP filters, optional pre-roll)
+//    and Recordings Store (optional saving of recordings)
+// 2a. Initialize Transcriber (backend of the configured provider) and Inserter (types text at the cursor),
+//     Indicator Manager (coordinates visual + audio + notification feedback)
+//     and Notification Indicator (desktop notifications, where a notification server runs)
+// 2b. Initialize Offline Queue (recordings whose transcription failed with a retryable error)
+//     and Usage Store (per-day totals of requests, audio and tokens)
+// 2c. Initialize Recovery Journal (audio of the active session on disk) and look for
+//     sessions left unfinished by a crash
+// 3. Initialize Hotkey Manager (registers Alt+Shift+V)
+// 4. Initialize Audio Indicator (sounds of the selected theme, unless sounds are disabled)
+// 5. Initialize Tray Manager (system tray icon and menu)
+// 6. In onReady callback (when tray is ready):
 //    - Initialize Visual Indicator (icon updates)
-//    - Initialize Audio Indicator (sound feedback)
-//    - Subscribe Indicator Manager to state changes
-//    - Register hotkey with callback that transitions states
-// 6. Run tray event loop (blocking)
+//    - Subscribe Indicator Manager and Recorder to state changes (one asynchronous subscriber,
+//      so the start sound ends before recording starts) and forward input level to indicators
+//    - Transcribe the recording when Recording -> Transcribing, then return to Idle
+//    - Retry queued recordings in the background and show the queue length in the tray
+//    - Show the unfinished sessions in the tray, to be transcribed or discarded
+//    - Register hotkeys: toggle (transitions states) and cancel
+// 7. Run tray event loop (blocking)
 //
-// State flow: Hotkey press → State transition → Indicator update (visual + audio)
+// State flow: Hotkey press → State transition → Indicator update (visual + audio) → Recorder start/stop
+// Session flow: each recording is a session started by the state machine and carried by its
+// changes: audio, captured window and transcript are stored on it, not in shared variables
+// Transcription flow: Recording → Transcribing (text inserted as it streams in) → Idle
+// Cancel flow: Recording → Idle (recording discarded) or Transcribing → Idle (request aborted), cancel sound
+// Offline flow: retryable failure → queue → background retry → insert into the original
+// window if it is still focused, otherwise copy to the clipboard, and notify
+// Notification flow: Error state, queued recording or transcript delivered later → notification;
+// with Preview, every transcript → notification with Copy and Retry
+// Recovery flow: Recording → audio journaled → crash → next start → tray or `vox recover`
+// → transcribed like a queued recording, or discarded
 // Cleanup: defer statements ensure proper resource cleanup on exit
-func run() error {
+func run(logFilePath string) error {
 	logger := platform.GetLogger()
 
-	// Initialize State Machine
-	stateMachine := state.NewStateMachine()
+	// Load configuration
+	configPath := config.Path()
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	logger.Info("Configuration loaded: %s", configPath)
+
+	// configMutex guards the settings changed from the tray menu while the pipeline
+	// reads them, and serializes saving the configuration
+	var configMutex sync.Mutex
+
+	// updateConfig applies a change of settings from the tray menu and persists it
+	// Nothing is saved if the change fails
+	updateConfig := func(change func() error) error {
+		configMutex.Lock()
+		defer configMutex.Unlock()
+		if err := change(); err != nil {
+			return err
+		}
+		if err := cfg.Save(configPath); err != nil {
+			logger.Warn("Failed to save configuration: %v", err)
+		}
+		return nil
+	}
+
+	// incognito reports whether audio must not be written to disk
+	incognito := func() bool {
+		configMutex.Lock()
+		defer configMutex.Unlock()
+		return cfg.Recordings.Incognito
+	}
+
+	// Initialize State Machine with the state limits from the configuration
+	table := state.DefaultTable()
+	table.Timeouts = map[state.State]time.Duration{
+		state.StateRecording:    time.Duration(cfg.Timeouts.MaxRecordingSeconds) * time.Second,
+		state.StateTranscribing: time.Duration(cfg.Timeouts.MaxTranscribingSeconds) * time.Second,
+		state.StateError:        time.Duration(cfg.Timeouts.ErrorDisplaySeconds) * time.Second,
+	}
+	stateMachine := state.NewStateMachineFromTable(table, nil)
 	logger.Info("State machine initialized")
 
+	// Initialize Recorder
+	audioRecorder, err := recorder.NewRecorder(dsp.NewChainFromConfig(cfg.Audio, recorder.SampleRate))
+	if err != nil {
+		return fmt.Errorf("failed to initialize recorder: %w", err)
+	}
+	defer func() {
+		if err := audioRecorder.Close(); err != nil {
+			logger.Error("Error closing recorder: %v", err)
+		} else {
+			logger.Info("Recorder closed")
+		}
+	}()
+
+	// setPreRoll enables or disables always-on capture and persists the choice
+	setPreRoll := func(enabled bool) error {
+		return updateConfig(func() error {
+			var duration time.Duration
+			if enabled {
+				duration = time.Duration(cfg.Audio.PreRollMs) * time.Millisecond
+			}
+			if err := audioRecorder.SetPreRoll(duration); err != nil {
+				return err
+			}
+			cfg.Audio.PreRollEnabled = enabled
+			return nil
+		})
+	}
+
+	if cfg.Audio.PreRollEnabled {
+		if err := audioRecorder.SetPreRoll(time.Duration(cfg.Audio.PreRollMs) * time.Millisecond); err != nil {
+			logger.Warn("Failed to enable pre-roll: %v", err)
+			cfg.Audio.PreRollEnabled = false
+		}
+	}
+
+	// Initialize Recordings Store (saving is optional and off in incognito mode)
+	recordingsStore := recordings.NewStore(cfg.Recordings.Dir, cfg.Recordings.MaxSizeMB, cfg.Recordings.MaxAgeDays)
+
+	// saveRecording stores the finished recording of a session for debugging and replay
+	// Returns nil if the recording was not saved
+	saveRecording := func(sess *session.Session) *recordings.Metadata {
+		if !cfg.Recordings.Enabled || incognito() {
+			return nil
+		}
+		samples, sampleRate := sess.Audio()
+		meta, err := recordingsStore.Save(samples, sampleRate, recordings.Metadata{
+			ID:        sess.ID,
+			StartedAt: sess.StartedAt,
+			Profile:   sess.Profile(),
+			Context:   sess.Context(),
+		})
+		if err != nil {
+			logger.Warn("Failed to save recording: %v", err)
+			return nil
+		}
+		return &meta
+	}
+
+	// setIncognito turns writing audio to disk (recordings, offline queue, recovery journal)
+	// off or on and persists the choice
+	setIncognito := func(enabled bool) error {
+		return updateConfig(func() error {
+			cfg.Recordings.Incognito = enabled
+			return nil
+		})
+	}
+
+	// Initialize Transcriber
+	transcriber, err := transcription.New(cfg.Transcription)
+	if err != nil {
+		return fmt.Errorf("failed to initialize transcriber: %w", err)
+	}
+	logger.Info("Transcriber initialized")
+
+	// Initialize Inserter (without it transcripts are only logged)
+	textInserter, err := inserter.NewInserter()
+	if err != nil {
+		logger.Warn("Failed to initialize text inserter: %v. Transcripts will not be inserted.", err)
+	} else {
+		logger.Info("Text inserter initialized")
+	}
+
+	// Variable to hold tray manager (will be initialized before the tray runs)
+	var trayManager tray.TrayManager
+
+	// Initialize Indicator Manager
+	indicatorManager := indicator.NewIndicatorManager()
+	logger.Info("Indicator manager initialized")
+
+	// Initialize Notification Indicator (desktop notifications, where a notification server runs)
+	if cfg.Notifications.Enabled {
+		notificationIndicator, err := indicator.NewNotificationIndicator()
+		if err != nil {
+			logger.Warn("Desktop notifications are unavailable: %v", err)
+		} else {
+			indicatorManager.SetNotificationIndicator(notificationIndicator)
+			defer notificationIndicator.Close()
+			logger.Info("Notification indicator initialized")
+		}
+	}
+
+	// Initialize Offline Queue
+	var offlineQueue *queue.Queue
+	if cfg.Queue.Enabled {
+		offlineQueue = queue.NewQueue(cfg.Queue.Dir, func(length int) {
+			trayManager.SetQueueLength(length)
+		})
+		logger.Info("Offline queue initialized: %s", cfg.Queue.Dir)
+	}
+
+	// Initialize Recovery Journal
+	var recoveryJournal *journal.Journal
+	if cfg.Recovery.Enabled {
+		recoveryJournal = journal.New(cfg.Recovery.Dir)
+		logger.Info("Recovery journal initialized: %s", cfg.Recovery.Dir)
+	}
+
+	// beginJournal starts journaling the audio of a session
+	// Returns nil if the session is not journaled
+	beginJournal := func(sess *session.Session) *journal.Session {
+		if recoveryJournal == nil || incognito() {
+			return nil
+		}
+		journaled, err := recoveryJournal.Begin(journal.Entry{
+			ID:         sess.ID,
+			StartedAt:  sess.StartedAt,
+			SampleRate: recorder.SampleRate,
+		})
+		if err != nil {
+			logger.Warn("Failed to journal session %s: %v", sess.ID, err)
+			return nil
+		}
+		return journaled
+	}
+
+	// Initialize Usage Store
+	var usageStore *usage.Store
+	if cfg.Usage.Enabled {
+		usageStore = usage.NewStore(cfg.Usage.Path)
+	}
+
+	// updateUsageSummary shows today's usage in the tray
+	updateUsageSummary := func() {
+		if usageStore == nil {
+			return
+		}
+		today := time.Now().Format(usage.DateLayout)
+		entries, err := usageStore.Entries(today, today)
+		if err != nil {
+			logger.Warn("Failed to read usage: %v", err)
+			return
+		}
+		trayManager.SetUsageSummary(usage.Summary(entries, cfg.Usage.Prices))
+	}
+
+	// recordUsage adds a finished transcription to the usage totals
+	recordUsage := func(result transcription.Result, samples []int16, sampleRate int) {
+		if usageStore == nil {
+			return
+		}
+		err := usageStore.Record(time.Now(), usage.Entry{
+			Provider:         result.Backend,
+			Model:            result.Model,
+			Requests:         1,
+			AudioSeconds:     float64(len(samples)) / float64(sampleRate),
+			PromptTokens:     result.Usage.PromptTokens,
+			CompletionTokens: result.Usage.CompletionTokens,
+			TotalTokens:      result.Usage.TotalTokens,
+		})
+		if err != nil {
+			logger.Warn("Failed to record usage: %v", err)
+			return
+		}
+		updateUsageSummary()
+	}
+
+	// transcribeLater transcribes a recording that is no longer in the pipeline (queued or recovered)
+	transcribeLater := func(ctx context.Context, samples []int16, sampleRate int) (string, error) {
+		if cfg.Transcription.TimeoutSeconds > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, time.Duration(cfg.Transcription.TimeoutSeconds)*time.Second)
+			defer cancel()
+		}
+
+		result, err := transcriber.Transcribe(ctx, transcription.Request{
+			Samples:    samples,
+			SampleRate: sampleRate,
+		}, nil)
+		if err != nil {
+			return "", err
+		}
+		recordUsage(result, samples, sampleRate)
+		return result.Text, nil
+	}
+
+	// deliverLater delivers a transcript of a queued or recovered recording (kind):
+	// inserted if the original window is focused and no recording is in progress,
+	// otherwise copied to the clipboard
+	deliverLater := func(text, window, kind string) {
+		if textInserter != nil && window != "" && stateMachine.GetState() == state.StateIdle {
+			if focused, err := inserter.FocusedWindow(); err == nil && focused == window {
+				if err := textInserter.Insert(text); err != nil {
+					logger.Warn("Failed to insert %s transcript: %v", kind, err)
+				} else {
+					logger.Info("The %s transcript was inserted: %d characters", kind, len(text))
+					trayManager.SetStatus("A " + kind + " transcript was inserted")
+					indicatorManager.Notify(indicator.Notification{
+						Summary: "A " + kind + " transcript was inserted",
+						Body:    preview(text),
+						Urgency: indicator.UrgencyLow,
+					})
+					return
+				}
+			}
+		}
+
+		// The transcript is not lost if the clipboard fails: it is in the log
+		if err := inserter.CopyToClipboard(text); err != nil {
+			logger.Error("Failed to copy %s transcript to the clipboard: %v. Transcript: %s", kind, err, text)
+			trayManager.SetStatus("A " + kind + " transcript could not be delivered, see the log")
+			indicatorManager.Notify(indicator.Notification{
+				Summary: "A " + kind + " transcript could not be delivered",
+				Body:    "The transcript is in the log: " + logFilePath,
+				Urgency: indicator.UrgencyNormal,
+			})
+			return
+		}
+		logger.Info("The %s transcript was copied to the clipboard: %d characters", kind, len(text))
+		trayManager.SetStatus("A " + kind + " transcript was copied to the clipboard")
+		indicatorManager.Notify(indicator.Notification{
+			Summary: "A " + kind + " transcript was copied to the clipboard",
+			Body:    preview(text),
+			Urgency: indicator.UrgencyLow,
+		})
+	}
+
+	// notifyTranscript previews a transcript in a notification, if enabled
+	// Copy puts the whole transcript on the clipboard; Retry transcribes the audio
+	// of the session again and delivers the new transcript like a queued one
+	notifyTranscript := func(sess *session.Session, text string) {
+		if !cfg.Notifications.Preview {
+			return
+		}
+		retry := func() {
+			samples, sampleRate := sess.Audio()
+			text, err := transcribeLater(context.Background(), samples, sampleRate)
+			if err != nil {
+				logger.Error("Failed to transcribe session %s again: %v", sess.ID, err)
+				trayManager.SetStatus("The transcription could not be retried, see the log")
+				return
+			}
+			if text == "" {
+				logger.Info("Session %s has no speech", sess.ID)
+				return
+			}
+			deliverLater(text, sess.ContextValue(session.ContextWindow), "retried")
+		}
+		indicatorManager.Notify(indicator.Notification{
+			Summary: "Transcript",
+			Body:    preview(text),
+			Urgency: indicator.UrgencyLow,
+			Actions: []indicator.Action{
+				{Label: "Copy", Run: func() {
+					if err := inserter.CopyToClipboard(text); err != nil {
+						logger.Error("Failed to copy the transcript to the clipboard: %v", err)
+					}
+				}},
+				{Label: "Retry", Run: retry},
+			},
+		})
+	}
+
+	// enqueue keeps the recording of a session whose transcription failed with a retryable error
+	enqueue := func(sess *session.Session, cause error) {
+		if offlineQueue == nil {
+			return
+		}
+		if incognito() {
+			logger.Info("Recording not queued for retry in incognito mode")
+			return
+		}
+		samples, sampleRate := sess.Audio()
+		item := queue.Item{Window: sess.ContextValue(session.ContextWindow)}
+		if _, err := offlineQueue.Add(samples, sampleRate, item, cause); err != nil {
+			logger.Error("Failed to queue recording: %v", err)
+			return
+		}
+		// Replaces the failure notification of the indicator manager (see Session.Queued)
+		sess.SetQueued()
+		trayManager.SetStatus("Transcription failed, the recording is queued for retry")
+		indicatorManager.Notify(indicator.Notification{
+			Summary: "Transcription failed, the recording is queued for retry",
+			Body:    cause.Error() + ". It will be transcribed once the provider is reachable",
+			Urgency: indicator.UrgencyNormal,
+		})
+	}
+
+	// transcribe converts the recording of a session to text and inserts it at the cursor
+	// Runs in its own goroutine while in the Transcribing state and returns to Idle,
+	// or to Error if the transcription failed
+	// The session is cancelled by the cancel action and by the Transcribing state timeout
+	// The journal of the session is removed when it ends: transcribed, queued or abandoned
+	transcribe := func(sess *session.Session, meta *recordings.Metadata, journaled *journal.Session) {
+		ctx, cancel := context.WithCancel(context.Background())
+		sess.SetCancel(cancel)
+
+		event := state.EventFinish
+		defer func() {
+			cancel()
+
+			if err := journaled.Finish(); err != nil {
+				logger.Warn("Failed to remove session %s from the journal: %v", sess.ID, err)
+			}
+
+			// Ignored if the Transcribing state has already timed out, or the session has ended
+			if err := stateMachine.FireFor(sess, event); err != nil && !errors.Is(err, state.ErrNoTransition) {
+				logger.Error("Error transitioning state: %v", err)
+			}
+		}()
+
+		// Streamed text is inserted word by word as it arrives
+		var buffer *inserter.WordBuffer
+		var onDelta func(text string) error
+		if textInserter != nil {
+			buffer = inserter.NewWordBuffer(textInserter)
+			onDelta = buffer.Write
+		}
+
+		// The deadline covers all retries and fallbacks of the Transcribing state
+		if cfg.Transcription.TimeoutSeconds > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, time.Duration(cfg.Transcription.TimeoutSeconds)*time.Second)
+			defer cancel()
+		}
+
+		samples, sampleRate := sess.Audio()
+		startedAt := time.Now()
+		result, err := transcriber.Transcribe(ctx, transcription.Request{
+			Samples:    samples,
+			SampleRate: sampleRate,
+		}, onDelta)
+		if err == nil && buffer != nil {
+			err = buffer.Flush()
+		}
+		latency := time.Since(startedAt)
+
+		if err != nil {
+			var streamErr *transcription.StreamError
+			switch {
+			case errors.Is(err, context.Canceled):
+				logger.Info("Transcription cancelled")
+			case errors.Is(err, transcription.ErrUnauthorized):
+				logger.Error("Transcription failed: the provider rejected the credentials, check the API key in %s: %v", configPath, err)
+			case errors.As(err, &streamErr) && buffer != nil:
+				logger.Error("Transcription stream broke, inserted so far: %q: %v", buffer.Inserted(), streamErr.Err)
+			case buffer != nil && buffer.Inserted() != "":
+				logger.Error("Transcription failed, inserted so far: %q: %v", buffer.Inserted(), err)
+			default:
+				logger.Error("Transcription failed: %v", err)
+			}
+
+			if !errors.Is(err, context.Canceled) {
+				event = state.EventFail
+			}
+
+			// Text already inserted cannot be taken back, so only untouched recordings are queued
+			if transcription.Retryable(err) && (buffer == nil || buffer.Inserted() == "") {
+				enqueue(sess, err)
+			}
+		} else {
+			logger.Info("Transcription completed by %s in %v: %d characters", result.Backend, latency.Round(time.Millisecond), len(result.Text))
+			recordUsage(result, samples, sampleRate)
+			if textInserter == nil {
+				logger.Info("Transcript: %s", result.Text)
+			}
+			if result.Text != "" {
+				notifyTranscript(sess, result.Text)
+			}
+		}
+
+		// On error, the session keeps the text inserted before the failure
+		transcript := result.Text
+		if err != nil && buffer != nil {
+			transcript = buffer.Inserted()
+		}
+		sess.SetResult(result.Backend, transcript, err)
+
+		if meta != nil {
+			meta.Backend = result.Backend
+			meta.Transcript = transcript
+			meta.LatencyMs = latency.Milliseconds()
+			if err != nil {
+				meta.Error = err.Error()
+			}
+			if err := recordingsStore.UpdateMetadata(*meta); err != nil {
+				logger.Warn("Failed to update recording metadata: %v", err)
+			}
+		}
+	}
+
+	// processQueued transcribes a queued recording and delivers the transcript
+	processQueued := func(ctx context.Context, item queue.Item, samples []int16) error {
+		text, err := transcribeLater(ctx, samples, item.SampleRate)
+		if err != nil {
+			if ctx.Err() == nil && !transcription.Retryable(err) {
+				// The queue keeps the recording; the user has to fix the cause (e.g. the API key)
+				indicatorManager.Notify(indicator.Notification{
+					Summary: "A queued recording could not be transcribed",
+					Body:    err.Error() + ". It is kept in " + cfg.Queue.Dir + " and retried when Vox restarts",
+					Urgency: indicator.UrgencyNormal,
+				})
+			}
+			return err
+		}
+		if text == "" {
+			logger.Info("Queued recording %s has no speech", item.ID)
+			return nil
+		}
+		deliverLater(text, item.Window, "queued")
+		return nil
+	}
+
+	// Sessions left unfinished by a crash, found at startup (so never the active session)
+	var (
+		recoveryMutex sync.Mutex // guards unfinished and serializes recovery actions
+		unfinished    []journal.Entry
+	)
+	if recoveryJournal != nil {
+		if unfinished, err = recoveryJournal.Unfinished(); err != nil {
+			logger.Warn("Failed to read the recovery journal: %v", err)
+		} else if len(unfinished) > 0 {
+			logger.Info("Found %d unfinished recordings in %s", len(unfinished), cfg.Recovery.Dir)
+		}
+	}
+
+	// recoverUnfinished transcribes the unfinished recordings and delivers the transcripts
+	// like queued ones; several transcripts are joined and copied to the clipboard
+	// Recordings that could not be transcribed are kept for another attempt
+	recoverUnfinished := func() {
+		recoveryMutex.Lock()
+		defer recoveryMutex.Unlock()
+
+		var kept []journal.Entry
+		var texts []string
+		window := ""
+		for _, entry := range unfinished {
+			samples, err := recoveryJournal.Load(entry.ID)
+			if err != nil {
+				logger.Error("Failed to load unfinished recording %s: %v", entry.ID, err)
+				kept = append(kept, entry)
+				continue
+			}
+			if len(samples) > 0 {
+				text, err := transcribeLater(context.Background(), samples, entry.SampleRate)
+				if err != nil {
+					logger.Error("Failed to transcribe unfinished recording %s: %v", entry.ID, err)
+					kept = append(kept, entry)
+					continue
+				}
+				if text != "" {
+					texts = append(texts, text)
+					window = entry.Context[session.ContextWindow]
+				}
+			}
+			logger.Info("Unfinished recording %s recovered: %.1f s", entry.ID, float64(len(samples))/float64(entry.SampleRate))
+			if err := recoveryJournal.Discard(entry.ID); err != nil {
+				logger.Warn("%v", err)
+			}
+		}
+		unfinished = kept
+		trayManager.SetRecoverable(len(unfinished))
+
+		switch {
+		case len(kept) > 0:
+			trayManager.SetStatus(fmt.Sprintf("%d unfinished recordings could not be transcribed, see the log", len(kept)))
+		case len(texts) == 0:
+			trayManager.SetStatus("The unfinished recordings have no speech")
+		case len(texts) == 1:
+			deliverLater(texts[0], window, "recovered")
+		default:
+			deliverLater(strings.Join(texts, "\n\n"), "", "recovered")
+		}
+	}
+
+	// discardUnfinished deletes the unfinished recordings
+	discardUnfinished := func() {
+		recoveryMutex.Lock()
+		defer recoveryMutex.Unlock()
+
+		var kept []journal.Entry
+		for _, entry := range unfinished {
+			if err := recoveryJournal.Discard(entry.ID); err != nil {
+				logger.Warn("%v", err)
+				kept = append(kept, entry)
+			}
+		}
+		logger.Info("%d unfinished recordings discarded", len(unfinished)-len(kept))
+		unfinished = kept
+		trayManager.SetRecoverable(len(unfinished))
+		trayManager.SetStatus("The unfinished recordings were discarded")
+	}
+
 	// Initialize Hotkey Manager
 	hotkeyManager := hotkey.NewHotkeyManager()
 	defer func() {
@@ -88,36 +702,129 @@ func run() error {
 		}
 	}()
 
-	// Initialize Indicator Manager
-	indicatorManager := indicator.NewIndicatorManager()
-	logger.Info("Indicator manager initialized")
+	// Load assets: the embedded defaults, replaced file by file from the override directory
+	appAssets := assets.New(cfg.Assets.Dir)
+	icons, err := fs.Sub(appAssets, "icons")
+	if err != nil {
+		return fmt.Errorf("failed to load icons: %w", err)
+	}
+	sounds, err := fs.Sub(appAssets, "sounds")
+	if err != nil {
+		return fmt.Errorf("failed to load sounds: %w", err)
+	}
+	logger.Info("Assets loaded, overrides: %s", cfg.Assets.Dir)
+
+	// Initialize Audio Indicator with the selected sound theme
+	var audioIndicator indicator.AudioIndicator
+	themes := []indicator.Theme{indicator.BuiltinTheme(sounds)}
+	if cfg.Audio.Enabled {
+		audioIndicator, err = indicator.NewAudioIndicator(themes[0], cfg.Audio.Volume, audio.Policy(cfg.Audio.PlaybackPolicy))
+		if err != nil {
+			logger.Warn("Failed to initialize audio indicator: %v", err)
+		} else {
+			indicatorManager.SetAudioIndicator(audioIndicator)
+			logger.Info("Audio indicator initialized")
+		}
+	} else {
+		logger.Info("Sounds are disabled")
+	}
 
-	// Get paths to assets
-	assetsPath := getAssetsPath()
-	iconsPath := filepath.Join(assetsPath, "icons")
-	soundsPath := filepath.Join(assetsPath, "sounds")
+	// selectSoundTheme switches to a theme by name and persists the choice
+	selectSoundTheme := func(name string) error {
+		for _, theme := range themes {
+			if theme.Name == name {
+				return updateConfig(func() error {
+					audioIndicator.SetTheme(theme)
+					cfg.Audio.Theme = name
+					return nil
+				})
+			}
+		}
+		return fmt.Errorf("unknown sound theme %q", name)
+	}
 
-	// Variable to hold tray manager (will be initialized in onReady)
-	var trayManager tray.TrayManager
+	// setVolume changes the global volume of the sounds and persists it
+	setVolume := func(volume float64) error {
+		return updateConfig(func() error {
+			audioIndicator.SetVolume(volume)
+			cfg.Audio.Volume = volume
+			return nil
+		})
+	}
+
+	// The built-in theme is used until the configured one is found
+	soundTheme := indicator.DefaultTheme
+	if audioIndicator != nil {
+		userThemes, err := indicator.LoadThemes(cfg.Audio.ThemesDir)
+		if err != nil {
+			logger.Warn("Failed to load sound themes: %v", err)
+		}
+		themes = append(themes, userThemes...)
+		logger.Info("Sound themes found: %d in %s", len(userThemes), cfg.Audio.ThemesDir)
+
+		for _, theme := range themes[1:] {
+			if theme.Name == cfg.Audio.Theme {
+				audioIndicator.SetTheme(theme)
+				soundTheme = theme.Name
+			}
+		}
+		if soundTheme != cfg.Audio.Theme && cfg.Audio.Theme != "" {
+			logger.Warn("Sound theme %q not found in %s, using the built-in sounds", cfg.Audio.Theme, cfg.Audio.ThemesDir)
+		}
+	}
 
 	// toggleRecording is the callback for Start/Stop menu item and hotkey
+	// Starts recording, or stops it and transcribes; ignored while transcribing
 	toggleRecording := func() {
-		currentState := stateMachine.GetState()
-		var nextState state.State
+		if err := stateMachine.Fire(state.EventToggle); err != nil && !errors.Is(err, state.ErrNoTransition) {
+			logger.Error("Error transitioning state: %v", err)
+		}
+	}
 
-		// Toggle between Idle and Recording
-		switch currentState {
-		case state.StateIdle:
-			nextState = state.StateRecording
+	// cancelActive is the callback for Cancel menu item and hotkey
+	// Discards the recording, aborts the transcription (which then returns to Idle)
+	// or dismisses the error
+	cancelActive := func() {
+		switch currentState := stateMachine.GetState(); currentState {
 		case state.StateRecording:
-			nextState = state.StateIdle
+			if err := stateMachine.Fire(state.EventCancel); err != nil {
+				logger.Error("Error transitioning state: %v", err)
+				return
+			}
+			logger.Info("Recording cancelled")
+		case state.StateTranscribing:
+			// The session cancels its transcription as soon as it starts, if it has not yet
+			if sess := stateMachine.Session(); sess != nil {
+				sess.Cancel()
+			}
+			logger.Info("Cancelling transcription")
+		case state.StateError:
+			if err := stateMachine.Fire(state.EventCancel); err != nil {
+				logger.Error("Error transitioning state: %v", err)
+			}
+			logger.Info("Error dismissed")
+			return
+		default:
+			logger.Info("Nothing to cancel in %s state", currentState)
+			return
 		}
+		indicatorManager.OnCancel()
+	}
 
-		if err := stateMachine.Transition(nextState); err != nil {
-			logger.Error("Error transitioning state: %v", err)
-		} else {
-			logger.Info("State transitioned: %s -> %s", currentState, nextState)
+	// saveSupportBundle writes version, state history and recent log to ~/.vox/support/
+	saveSupportBundle := func() {
+		path, err := support.WriteBundle(filepath.Join(filepath.Dir(configPath), "support"), support.Info{
+			Version: Version,
+			History: stateMachine.History(),
+			LogPath: logFilePath,
+		}, time.Now())
+		if err != nil {
+			logger.Error("Failed to save support bundle: %v", err)
+			trayManager.SetStatus("Failed to save the support bundle, see the log")
+			return
 		}
+		logger.Info("Support bundle saved: %s", path)
+		trayManager.SetStatus("Support bundle saved: " + path)
 	}
 
 	// onReady callback - called when tray is initialized
@@ -125,7 +832,7 @@ func run() error {
 		logger.Info("Tray is ready, initializing components...")
 
 		// Initialize Visual Indicator
-		visualIndicator, err := indicator.NewVisualIndicator(trayManager, iconsPath)
+		visualIndicator, err := indicator.NewVisualIndicator(trayManager, icons)
 		if err != nil {
 			logger.Warn("Failed to initialize visual indicator: %v", err)
 		} else {
@@ -138,33 +845,103 @@ func run() error {
 			}
 		}
 
-		// Initialize Audio Indicator
-		audioIndicator, err := indicator.NewAudioIndicator(soundsPath)
-		if err != nil {
-			logger.Warn("Failed to initialize audio indicator: %v", err)
-		} else {
-			indicatorManager.SetAudioIndicator(audioIndicator)
-			logger.Info("Audio indicator initialized")
-		}
-
-		// Subscribe Indicator Manager to state changes
-		stateMachine.Subscribe(indicatorManager.OnStateChange)
-		logger.Info("Indicator manager subscribed to state changes")
-
 		// Subscribe to state changes to update tray menu
-		stateMachine.Subscribe(func(oldState, newState state.State) {
-			isRecording := newState == state.StateRecording
+		stateMachine.Subscribe(func(change state.Change) {
+			isRecording := change.To == state.StateRecording
 			trayManager.UpdateToggleMenuItem(isRecording)
+			trayManager.SetCancelEnabled(change.To != state.StateIdle)
+			// A new recording clears the last status message
+			if isRecording {
+				trayManager.SetStatus("")
+			}
 		})
 		logger.Info("Tray menu subscribed to state changes")
 
+		// Subscribe Indicator Manager and Recorder to state changes
+		// Subscribers are called asynchronously, so both share one subscriber: the indicators
+		// are updated first and the recorder starts after the start sound, which is not captured
+		var journaled *journal.Session // journal of the session being recorded, nil if not journaled
+		stateMachine.Subscribe(func(change state.Change) {
+			indicatorManager.OnStateChange(change)
+
+			sess := change.Session
+			switch {
+			case change.From == state.StateTranscribing && change.Event == state.EventTimeout:
+				logger.Warn("Transcription of session %s took too long, cancelling it", sess.ID)
+				sess.Cancel()
+			case change.To == state.StateRecording:
+				logger.Info("Session %s started", sess.ID)
+
+				// Captured frames go to the journal as they arrive, so a crash loses at most a moment
+				journaled = beginJournal(sess)
+				if journaled != nil {
+					audioRecorder.SetFrameCallback(journaled.Write)
+				}
+				if err := audioRecorder.Start(); err != nil {
+					logger.Error("Failed to start recording: %v", err)
+				}
+			case change.From == state.StateRecording:
+				samples, err := audioRecorder.Stop()
+				if err != nil {
+					logger.Error("Failed to stop recording: %v", err)
+				}
+				audioRecorder.SetFrameCallback(nil)
+				sessionJournal := journaled
+				journaled = nil
+				sess.SetAudio(samples, recorder.SampleRate, change.Time)
+				logger.Info("Time spent recording: %v", sess.RecordingDuration().Round(time.Millisecond))
+
+				// Returning to Idle straight from Recording means the recording was cancelled
+				if change.To != state.StateTranscribing {
+					logger.Info("Recording discarded: %.1f s", float64(len(samples))/recorder.SampleRate)
+					if err := sessionJournal.Finish(); err != nil {
+						logger.Warn("Failed to remove session %s from the journal: %v", sess.ID, err)
+					}
+					return
+				}
+
+				sess.SetProfile(cfg.Transcription.Provider.Preset)
+
+				// The window is saved with the recording, and a queued or recovered
+				// transcript is only inserted if this window is focused again
+				if window, err := inserter.FocusedWindow(); err == nil {
+					sess.SetContext(session.ContextWindow, window)
+				}
+				if err := sessionJournal.Transcribing(sess.Context()); err != nil {
+					logger.Warn("Failed to journal session %s: %v", sess.ID, err)
+				}
+				go transcribe(sess, saveRecording(sess), sessionJournal)
+			}
+		})
+		logger.Info("Indicator manager and recorder subscribed to state changes")
+
+		// Show microphone input level in the tray icon while recording
+		audioRecorder.SetLevelCallback(indicatorManager.OnLevelChange)
+
+		// Show today's usage in the tray
+		updateUsageSummary()
+
+		// Retry queued recordings in the background
+		if offlineQueue != nil {
+			trayManager.SetQueueLength(offlineQueue.Len())
+			go offlineQueue.Run(context.Background(), processQueued)
+			logger.Info("Offline queue started")
+		}
+
+		// Offer the recordings left unfinished by a crash
+		if recoveryJournal != nil {
+			recoveryMutex.Lock()
+			trayManager.SetRecoverable(len(unfinished))
+			recoveryMutex.Unlock()
+		}
+
 		// Register hotkey Alt+Shift+V
 		hk := hotkey.Hotkey{
 			Modifiers: []hotkey.Modifier{hotkey.ModAlt, hotkey.ModShift},
 			Key:       hotkey.KeyV,
 		}
 
-		// Hotkey callback - toggles between Idle and Recording states
+		// Hotkey callback - starts recording, or stops it and starts transcription
 		hotkeyCallback := func() {
 			logger.Info("Hotkey pressed: %s", hk.String())
 			toggleRecording()
@@ -177,6 +954,21 @@ func run() error {
 			logger.Info("Hotkey registered: %s", hk.String())
 		}
 
+		// Register the cancel hotkey (default Alt+Shift+X)
+		if cfg.Hotkey.Cancel.Enabled {
+			cancelHk, err := cancelHotkey(cfg.Hotkey.Cancel)
+			if err != nil {
+				logger.Warn("Invalid cancel hotkey in %s: %v", configPath, err)
+			} else if err := hotkeyManager.Register(cancelHk, func() {
+				logger.Info("Hotkey pressed: %s", cancelHk.String())
+				cancelActive()
+			}); err != nil {
+				logger.Warn("Failed to register cancel hotkey %s: %v", cancelHk.String(), err)
+			} else {
+				logger.Info("Cancel hotkey registered: %s", cancelHk.String())
+			}
+		}
+
 		logger.Info("Application initialized successfully")
 	}
 
@@ -188,6 +980,23 @@ func run() error {
 
 	// Initialize Tray Manager
 	trayManager = tray.NewTrayManager(onReady, onExit, toggleRecording)
+	trayManager.SetCancelHandler(cancelActive)
+	trayManager.SetSupportBundleHandler(saveSupportBundle)
+	trayManager.SetPreRollHandler(cfg.Audio.PreRollEnabled, setPreRoll)
+	// Shown even when recordings are not saved: incognito also stops the queue and the journal
+	trayManager.SetIncognitoHandler(cfg.Recordings.Incognito, setIncognito)
+	if audioIndicator != nil {
+		names := make([]string, len(themes))
+		for i, theme := range themes {
+			names[i] = theme.Name
+		}
+		trayManager.SetSoundThemeHandler(names, soundTheme, selectSoundTheme)
+		trayManager.SetVolumeHandler(cfg.Audio.Volume, setVolume)
+	}
+	if recoveryJournal != nil {
+		// Transcription takes a while, so the menu is not blocked
+		trayManager.SetRecoveryHandler(func() { go recoverUnfinished() }, func() { go discardUnfinished() })
+	}
 	logger.Info("Tray manager created")
 
 	// Run tray (blocking call)
@@ -197,56 +1006,249 @@ func run() error {
 	return nil
 }
 
-// getAssetsPath returns the path to the assets directory
-// It tries multiple locations in the following order:
-// 1. Current working directory (development mode)
-// 2. Next to executable (production mode)
-// 3. Parent directory of executable (some build configurations)
-func getAssetsPath() string {
-	logger := platform.GetLogger()
+// cancelHotkey builds the cancel hotkey from the configuration
+func cancelHotkey(cfg config.CancelHotkeyConfig) (hotkey.Hotkey, error) {
+	key, err := hotkey.ParseKey(cfg.Key)
+	if err != nil {
+		return hotkey.Hotkey{}, err
+	}
+
+	var modifiers []hotkey.Modifier
+	if cfg.UseCtrl {
+		modifiers = append(modifiers, hotkey.ModCtrl)
+	}
+	if cfg.UseAlt {
+		modifiers = append(modifiers, hotkey.ModAlt)
+	}
+	if cfg.UseShift {
+		modifiers = append(modifiers, hotkey.ModShift)
+	}
+
+	return hotkey.Hotkey{Modifiers: modifiers, Key: key}, nil
+}
+
+// previewLength is the maximum number of characters of a transcript shown in a notification
+const previewLength = 200
+
+// preview shortens a transcript for a notification
+func preview(text string) string {
+	runes := []rune(text)
+	if len(runes) <= previewLength {
+		return text
+	}
+	return strings.TrimSpace(string(runes[:previewLength])) + "…"
+}
+
+// runUsage prints the usage totals recorded in a date range
+func runUsage(args []string) error {
+	flags := flag.NewFlagSet("usage", flag.ContinueOnError)
+	from := flags.String("from", "", "first day to include, YYYY-MM-DD")
+	to := flags.String("to", "", "last day to include, YYYY-MM-DD")
+	today := flags.Bool("today", false, "only today")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
 
-	// Try current working directory first (development mode)
-	cwd, err := os.Getwd()
-	if err == nil {
-		assetsPath := filepath.Join(cwd, "assets")
-		if _, err := os.Stat(assetsPath); err == nil {
-			logger.Info("Assets found in working directory: %s", assetsPath)
-			return assetsPath
+	if *today {
+		*from = time.Now().Format(usage.DateLayout)
+		*to = *from
+	}
+	for _, date := range []string{*from, *to} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse(usage.DateLayout, date); err != nil {
+			return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
 		}
 	}
 
-	// Try to find assets directory relative to executable
-	exePath, err := os.Executable()
+	cfg, err := config.Load(config.Path())
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	entries, err := usage.NewStore(cfg.Usage.Path).Entries(*from, *to)
 	if err != nil {
-		logger.Warn("Failed to get executable path: %v", err)
-		return "assets"
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Println("No usage recorded")
+		return nil
+	}
+
+	fmt.Println()
+	return usage.Report(os.Stdout, entries, cfg.Usage.Prices)
+}
+
+// runRecover lists the sessions left unfinished by a crash, or transcribes or discards them
+// Meant for when Vox is not running: the running application offers them in the tray
+func runRecover(args []string) error {
+	flags := flag.NewFlagSet("recover", flag.ContinueOnError)
+	transcribe := flags.Bool("transcribe", false, "transcribe the recordings and print the transcripts")
+	discard := flags.Bool("discard", false, "delete the recordings")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *transcribe && *discard {
+		return errors.New("-transcribe and -discard cannot be used together")
+	}
+
+	cfg, err := config.Load(config.Path())
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	recoveryJournal := journal.New(cfg.Recovery.Dir)
+	entries, err := recoveryJournal.Unfinished()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Println("No unfinished recordings")
+		return nil
+	}
+
+	var transcriber transcription.Transcriber
+	if *transcribe {
+		if transcriber, err = transcription.New(cfg.Transcription); err != nil {
+			return fmt.Errorf("failed to initialize transcriber: %w", err)
+		}
+	}
+
+	// transcribeEntry applies the same time limit as the application
+	transcribeEntry := func(samples []int16, sampleRate int) (string, error) {
+		ctx := context.Background()
+		if cfg.Transcription.TimeoutSeconds > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, time.Duration(cfg.Transcription.TimeoutSeconds)*time.Second)
+			defer cancel()
+		}
+		result, err := transcriber.Transcribe(ctx, transcription.Request{
+			Samples:    samples,
+			SampleRate: sampleRate,
+		}, nil)
+		return result.Text, err
 	}
 
-	exeDir := filepath.Dir(exePath)
+	failed := 0
+	for _, entry := range entries {
+		samples, err := recoveryJournal.Load(entry.ID)
+		if err != nil {
+			return err
+		}
+		duration := float64(len(samples)) / float64(entry.SampleRate)
+		fmt.Printf("\n%s (started %s, %.1f s, interrupted while %s)\n",
+			entry.ID, entry.StartedAt.Format("2006-01-02 15:04:05"), duration, entry.Stage)
 
-	// Check if assets directory exists next to executable
-	assetsPath := filepath.Join(exeDir, "assets")
-	if _, err := os.Stat(assetsPath); err == nil {
-		logger.Info("Assets found next to executable: %s", assetsPath)
-		return assetsPath
+		switch {
+		case *discard:
+			if err := recoveryJournal.Discard(entry.ID); err != nil {
+				return err
+			}
+			fmt.Println("  Discarded")
+		case *transcribe:
+			if len(samples) > 0 {
+				text, err := transcribeEntry(samples, entry.SampleRate)
+				if err != nil {
+					fmt.Printf("  FAILED: %v\n", err)
+					failed++
+					continue
+				}
+				fmt.Printf("  %s\n", text)
+			}
+			if err := recoveryJournal.Discard(entry.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d recordings failed, they are kept", failed, len(entries))
 	}
+	return nil
+}
 
-	// Check if assets directory exists in parent directory
-	assetsPath = filepath.Join(exeDir, "..", "assets")
-	if _, err := os.Stat(assetsPath); err == nil {
-		logger.Info("Assets found in parent directory: %s", assetsPath)
-		return assetsPath
+// runAssetsExport writes the default icons and sounds into the override directory
+// (or -dir), where they can be edited to replace the defaults
+func runAssetsExport(args []string) error {
+	flags := flag.NewFlagSet("assets export", flag.ContinueOnError)
+	dir := flags.String("dir", "", "directory to write to, the configured override directory by default")
+	overwrite := flags.Bool("overwrite", false, "replace files that already exist")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *dir == "" {
+		cfg, err := config.Load(config.Path())
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		*dir = cfg.Assets.Dir
 	}
 
-	// Default to "assets" relative to current directory
-	logger.Warn("Assets directory not found, using default: assets")
-	return "assets"
+	written, err := assets.Export(*dir, *overwrite)
+	for _, path := range written {
+		fmt.Println(path)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\n%d files written to %s\n", len(written), *dir)
+	return nil
 }
 
+// runBackendTest sends a test clip to the configured provider and its fallbacks
+// and prints the result of each; returns an error if any of them failed
+func runBackendTest(args []string) error {
+	flags := flag.NewFlagSet("backend test", flag.ContinueOnError)
+	timeout := flags.Duration("timeout", 30*time.Second, "time limit for the whole check")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(config.Path())
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	results := transcription.Check(ctx, cfg.Transcription)
+
+	failed := 0
+	for _, result := range results {
+		status := "-"
+		if result.StatusCode != 0 {
+			status = fmt.Sprintf("%d", result.StatusCode)
+		}
+		outcome := "OK"
+		if result.Err != nil {
+			outcome = fmt.Sprintf("FAILED: %v", result.Err)
+			failed++
+		}
+
+		fmt.Printf("\n%s (backend: %s, model: %s)\n", result.Provider, result.Backend, result.Model)
+		fmt.Printf("  HTTP status: %s\n", status)
+		fmt.Printf("  Latency:     %v\n", result.Latency.Round(time.Millisecond))
+		fmt.Printf("  Reply:       %q\n", result.Reply)
+		fmt.Printf("  Audio input: %s\n", result.Audio)
+		fmt.Printf("  Result:      %s\n", outcome)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d providers failed", failed, len(results))
+	}
+	return nil
+}
 
 func printHelp() {
 	fmt.Println("\nUsage:")
-	fmt.Println("  vox           Start the application")
-	fmt.Println("  vox version   Show version information")
-	fmt.Println("  vox help      Show this help message")
+	fmt.Println("  vox                Start the application")
+	fmt.Println("  vox assets export  Write the default icons and sounds for customization (-dir, -overwrite)")
+	fmt.Println("  vox backend test   Send a test clip to the configured providers (-timeout)")
+	fmt.Println("  vox recover        List recordings interrupted by a crash (-transcribe, -discard)")
+	fmt.Println("  vox usage          Show transcription usage (-from, -to YYYY-MM-DD, -today)")
+	fmt.Println("  vox version        Show version information")
+	fmt.Println("  vox help           Show this help message")
 }
@@ -3,30 +3,32 @@ package main
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
-// TestGetAssetsPath tests the asset path resolution logic
-func TestGetAssetsPath(t *testing.T) {
-	// Save original working directory
-	originalWd, err := os.Getwd()
-	if err != nil {
-		t.Fatalf("Failed to get working directory: %v", err)
+// TestAssetsExport tests that the default assets are written to the given directory
+func TestAssetsExport(t *testing.T) {
+	dir := t.TempDir()
+	if err := runAssetsExport([]string{"-dir", dir}); err != nil {
+		t.Fatalf("Export failed: %v", err)
 	}
-	defer os.Chdir(originalWd)
-
-	// Test that getAssetsPath returns a valid path
-	assetsPath := getAssetsPath()
-	if assetsPath == "" {
-		t.Error("getAssetsPath returned empty string")
+	for _, name := range []string{"icons/idle_24.png", "icons/idle.ico", "sounds/start_recording.wav"} {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name))); err != nil {
+			t.Errorf("Expected %s to be exported: %v", name, err)
+		}
 	}
+}
 
-	// Verify the path is reasonable (contains "assets")
-	if !filepath.IsAbs(assetsPath) && assetsPath != "assets" {
-		// If not absolute, should be relative path containing "assets"
-		if filepath.Base(assetsPath) != "assets" {
-			t.Errorf("Expected path to end with 'assets', got: %s", assetsPath)
-		}
+// TestPreview tests that long transcripts are shortened for notifications
+func TestPreview(t *testing.T) {
+	if got := preview("hello"); got != "hello" {
+		t.Errorf("Expected a short transcript unchanged, got %q", got)
+	}
+	long := strings.Repeat("слово ", previewLength)
+	got := preview(long)
+	if !strings.HasSuffix(got, "…") || len([]rune(got)) > previewLength+1 {
+		t.Errorf("Expected the transcript to be shortened, got %d characters", len([]rune(got)))
 	}
 }
 
@@ -5,11 +5,12 @@ go 1.25.5
 require (
 	fyne.io/systray v1.12.0
 	github.com/ebitengine/oto/v3 v3.1.0
+	github.com/gen2brain/malgo v0.11.24
+	github.com/godbus/dbus/v5 v5.1.0
 	golang.design/x/hotkey v0.4.1
 )
 
 require (
 	github.com/ebitengine/purego v0.5.0 // indirect
-	github.com/godbus/dbus/v5 v5.1.0 // indirect
 	golang.org/x/sys v0.41.0 // indirect
 )
@@ -0,0 +1,204 @@
+// Package audio plays short feedback sounds without blocking the caller.
+//
+// Sounds are decoded once (DecodeWAV) and played by an Engine, which mixes them
+// through ebitengine/oto. Play returns at once; the engine watches each player
+// until it has finished, then closes it. Whether a new sound cuts off the one
+// playing or plays on top of it is the engine's Policy.
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/d-mozulyov/vox/internal/platform"
+	"github.com/ebitengine/oto/v3"
+)
+
+const (
+	// SampleRate is the output rate; sounds are converted to it when decoded
+	SampleRate = 44100
+
+	channelCount = 2
+
+	// pollInterval is how often a player is checked for the end of its sound
+	pollInterval = 10 * time.Millisecond
+)
+
+// Policy decides what happens to a sound still playing when the next one starts
+type Policy string
+
+const (
+	// PolicyInterrupt stops the sound playing, so only the latest one is heard
+	PolicyInterrupt Policy = "interrupt"
+	// PolicyOverlap mixes the new sound with the ones playing
+	PolicyOverlap Policy = "overlap"
+)
+
+// player is the part of *oto.Player the engine uses
+type player interface {
+	Play()
+	Pause()
+	IsPlaying() bool
+	Close() error
+}
+
+// playerFactory creates players, *oto.Context in production
+type playerFactory interface {
+	newPlayer(r io.Reader) player
+}
+
+// otoContext adapts *oto.Context to playerFactory
+type otoContext struct {
+	context *oto.Context
+}
+
+func (c otoContext) newPlayer(r io.Reader) player {
+	return c.context.NewPlayer(r)
+}
+
+// Engine plays sounds through a single audio output
+type Engine struct {
+	factory playerFactory
+	policy  Policy
+
+	mutex  sync.Mutex
+	active map[*playback]struct{}
+}
+
+// playback is one sound being played
+type playback struct {
+	player  player
+	once    sync.Once
+	stopped chan struct{} // closed to interrupt the sound
+	done    chan struct{} // closed when the player is closed
+}
+
+// NewEngine opens the audio output
+// Only one engine may exist per process: the output cannot be opened twice
+func NewEngine(policy Policy) (*Engine, error) {
+	context, ready, err := oto.NewContext(&oto.NewContextOptions{
+		SampleRate:   SampleRate,
+		ChannelCount: channelCount,
+		Format:       oto.FormatSignedInt16LE,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize audio context: %w", err)
+	}
+
+	// Wait for the audio context to be ready
+	<-ready
+
+	return newEngine(otoContext{context: context}, policy), nil
+}
+
+// newEngine creates an engine on top of a player factory
+// An unknown policy falls back to PolicyInterrupt
+func newEngine(factory playerFactory, policy Policy) *Engine {
+	if policy != PolicyInterrupt && policy != PolicyOverlap {
+		if policy != "" {
+			platform.GetLogger().Warn("Unknown sound playback policy %q, using %s", policy, PolicyInterrupt)
+		}
+		policy = PolicyInterrupt
+	}
+	return &Engine{
+		factory: factory,
+		policy:  policy,
+		active:  make(map[*playback]struct{}),
+	}
+}
+
+// Play starts a sound with its samples scaled by gain and returns at once
+// The returned channel is closed when the sound has finished or was interrupted,
+// right away if there is nothing to play
+func (e *Engine) Play(sound *Sound, gain float64) <-chan struct{} {
+	if sound == nil || len(sound.pcm) == 0 || gain <= 0 {
+		done := make(chan struct{})
+		close(done)
+		return done
+	}
+
+	pcm := sound.pcm
+	if gain != 1 {
+		// The decoded sound is shared, so it is scaled in a copy
+		pcm = append([]byte(nil), pcm...)
+		applyGain(pcm, gain)
+	}
+
+	p := &playback{
+		player:  e.factory.newPlayer(bytes.NewReader(pcm)),
+		stopped: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	e.mutex.Lock()
+	if e.policy == PolicyInterrupt {
+		for other := range e.active {
+			other.interrupt()
+		}
+	}
+	e.active[p] = struct{}{}
+	e.mutex.Unlock()
+
+	p.player.Play()
+	go e.watch(p)
+	return p.done
+}
+
+// Stop interrupts all sounds
+func (e *Engine) Stop() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	for p := range e.active {
+		p.interrupt()
+	}
+}
+
+// watch waits until a sound has finished or was interrupted, then closes its player
+func (e *Engine) watch(p *playback) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for playing := true; playing; {
+		select {
+		case <-p.stopped:
+			playing = false
+		case <-ticker.C:
+			playing = p.player.IsPlaying()
+		}
+	}
+
+	if err := p.player.Close(); err != nil {
+		platform.GetLogger().Warn("Failed to close sound player: %v", err)
+	}
+
+	e.mutex.Lock()
+	delete(e.active, p)
+	e.mutex.Unlock()
+	close(p.done)
+}
+
+// interrupt silences the sound at once; watch then closes the player
+func (p *playback) interrupt() {
+	p.once.Do(func() {
+		p.player.Pause()
+		close(p.stopped)
+	})
+}
+
+// applyGain scales 16-bit PCM samples in place
+// Samples pushed past full scale by a gain above 1 are clipped instead of wrapping around
+func applyGain(pcm []byte, gain float64) {
+	if gain == 1 {
+		return
+	}
+	for i := 0; i+1 < len(pcm); i += 2 {
+		sample := float64(int16(binary.LittleEndian.Uint16(pcm[i:]))) * gain
+		sample = math.Max(math.MinInt16, math.Min(math.MaxInt16, math.Round(sample)))
+		binary.LittleEndian.PutUint16(pcm[i:], uint16(int16(sample)))
+	}
+}
@@ -0,0 +1,250 @@
+package audio
+
+import (
+	"encoding/binary"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakePlayer plays until finish is called
+type fakePlayer struct {
+	mutex   sync.Mutex
+	data    []byte
+	playing bool
+	paused  bool
+	closed  bool
+}
+
+func (p *fakePlayer) Play() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.playing = true
+}
+
+func (p *fakePlayer) Pause() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.playing = false
+	p.paused = true
+}
+
+func (p *fakePlayer) IsPlaying() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.playing
+}
+
+func (p *fakePlayer) Close() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.closed = true
+	return nil
+}
+
+func (p *fakePlayer) finish() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.playing = false
+}
+
+func (p *fakePlayer) state() (paused, closed bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.paused, p.closed
+}
+
+// fakeFactory records the players it creates
+type fakeFactory struct {
+	players []*fakePlayer
+}
+
+func (f *fakeFactory) newPlayer(r io.Reader) player {
+	data, _ := io.ReadAll(r)
+	p := &fakePlayer{data: data}
+	f.players = append(f.players, p)
+	return p
+}
+
+// wavFile builds a 16-bit PCM WAV file
+func wavFile(rate, channels int, samples ...int16) []byte {
+	data := make([]byte, 44+len(samples)*2)
+	copy(data, "RIFF")
+	binary.LittleEndian.PutUint32(data[4:], uint32(36+len(samples)*2))
+	copy(data[8:], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(data[16:], 16)
+	binary.LittleEndian.PutUint16(data[20:], 1)
+	binary.LittleEndian.PutUint16(data[22:], uint16(channels))
+	binary.LittleEndian.PutUint32(data[24:], uint32(rate))
+	binary.LittleEndian.PutUint32(data[28:], uint32(rate*channels*2))
+	binary.LittleEndian.PutUint16(data[32:], uint16(channels*2))
+	binary.LittleEndian.PutUint16(data[34:], 16)
+	copy(data[36:], "data")
+	binary.LittleEndian.PutUint32(data[40:], uint32(len(samples)*2))
+	for i, sample := range samples {
+		binary.LittleEndian.PutUint16(data[44+i*2:], uint16(sample))
+	}
+	return data
+}
+
+// frames returns the left and right channels of a decoded sound
+func frames(s *Sound) (left, right []int16) {
+	for i := 0; i+3 < len(s.pcm); i += 4 {
+		left = append(left, int16(binary.LittleEndian.Uint16(s.pcm[i:])))
+		right = append(right, int16(binary.LittleEndian.Uint16(s.pcm[i+2:])))
+	}
+	return left, right
+}
+
+// TestDecodeWAV tests channel and sample rate conversion
+func TestDecodeWAV(t *testing.T) {
+	sound, err := DecodeWAV(wavFile(SampleRate, 1, 100, -200))
+	if err != nil {
+		t.Fatalf("Failed to decode mono: %v", err)
+	}
+	left, right := frames(sound)
+	if len(left) != 2 || left[1] != -200 || right[1] != -200 {
+		t.Errorf("Expected mono duplicated to both channels, got %v %v", left, right)
+	}
+
+	sound, err = DecodeWAV(wavFile(SampleRate, 2, 100, -100, 200, -200))
+	if err != nil {
+		t.Fatalf("Failed to decode stereo: %v", err)
+	}
+	if left, right := frames(sound); len(left) != 2 || left[1] != 200 || right[1] != -200 {
+		t.Errorf("Expected stereo kept, got %v %v", left, right)
+	}
+
+	// Half the rate doubles the frames, interpolating between samples
+	sound, err = DecodeWAV(wavFile(SampleRate/2, 1, 0, 1000, 2000))
+	if err != nil {
+		t.Fatalf("Failed to decode at another rate: %v", err)
+	}
+	if left, _ := frames(sound); len(left) != 6 || left[1] != 500 || left[2] != 1000 {
+		t.Errorf("Unexpected resampled sound: %v", left)
+	}
+	if sound.Duration() != 6*time.Second/SampleRate {
+		t.Errorf("Unexpected duration: %v", sound.Duration())
+	}
+
+	eightBit := wavFile(SampleRate, 1, 0)
+	binary.LittleEndian.PutUint16(eightBit[34:], 8)
+	for name, data := range map[string][]byte{
+		"not a WAV": []byte("hello, world"),
+		"8-bit":     eightBit,
+		"no data":   wavFile(SampleRate, 1)[:36],
+	} {
+		if _, err := DecodeWAV(data); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// TestPlayInterrupt tests that a new sound stops the previous one and players are closed
+func TestPlayInterrupt(t *testing.T) {
+	factory := &fakeFactory{}
+	engine := newEngine(factory, PolicyInterrupt)
+	sound, _ := DecodeWAV(wavFile(SampleRate, 1, 1000, 1000))
+
+	first := engine.Play(sound, 1)
+	second := engine.Play(sound, 1)
+
+	select {
+	case <-first:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the first sound to be interrupted")
+	}
+	if paused, closed := factory.players[0].state(); !paused || !closed {
+		t.Errorf("Expected the first player paused and closed, got %v and %v", paused, closed)
+	}
+
+	factory.players[1].finish()
+	select {
+	case <-second:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the second sound to finish")
+	}
+	if paused, closed := factory.players[1].state(); paused || !closed {
+		t.Errorf("Expected the second player to play to the end and be closed, got %v and %v", paused, closed)
+	}
+}
+
+// TestPlayOverlap tests that overlapping sounds play to the end
+func TestPlayOverlap(t *testing.T) {
+	factory := &fakeFactory{}
+	engine := newEngine(factory, PolicyOverlap)
+	sound, _ := DecodeWAV(wavFile(SampleRate, 1, 1000, 1000))
+
+	first := engine.Play(sound, 1)
+	second := engine.Play(sound, 1)
+
+	select {
+	case <-first:
+		t.Fatal("Expected the first sound to keep playing")
+	case <-time.After(5 * pollInterval):
+	}
+	if !factory.players[0].IsPlaying() || !factory.players[1].IsPlaying() {
+		t.Error("Expected both sounds to play")
+	}
+
+	engine.Stop()
+	<-first
+	<-second
+	if _, closed := factory.players[1].state(); !closed {
+		t.Error("Expected Stop to close all players")
+	}
+}
+
+// TestApplyGain tests that samples are scaled and limited to the 16-bit range
+func TestApplyGain(t *testing.T) {
+	samples := []int16{1000, -1000, 30000, -30000, 0}
+	pcm := make([]byte, len(samples)*2)
+	for i, sample := range samples {
+		binary.LittleEndian.PutUint16(pcm[i*2:], uint16(sample))
+	}
+
+	applyGain(pcm, 2)
+
+	want := []int16{2000, -2000, 32767, -32768, 0}
+	for i, expected := range want {
+		if got := int16(binary.LittleEndian.Uint16(pcm[i*2:])); got != expected {
+			t.Errorf("Sample %d: expected %d, got %d", i, expected, got)
+		}
+	}
+
+	applyGain(pcm, 0.5)
+	if got := int16(binary.LittleEndian.Uint16(pcm)); got != 1000 {
+		t.Errorf("Expected 1000 after halving, got %d", got)
+	}
+}
+
+// TestPlayGain tests that gain scales a copy of the sound with clipping protection
+func TestPlayGain(t *testing.T) {
+	factory := &fakeFactory{}
+	engine := newEngine(factory, PolicyInterrupt)
+	sound, _ := DecodeWAV(wavFile(SampleRate, 1, 1000, 30000))
+
+	engine.Play(sound, 2)
+	played := factory.players[0].data
+	if got := int16(binary.LittleEndian.Uint16(played)); got != 2000 {
+		t.Errorf("Expected 2000, got %d", got)
+	}
+	if got := int16(binary.LittleEndian.Uint16(played[4:])); got != 32767 {
+		t.Errorf("Expected the loud sample clipped to 32767, got %d", got)
+	}
+	if left, _ := frames(sound); left[0] != 1000 {
+		t.Errorf("Expected the decoded sound unchanged, got %d", left[0])
+	}
+
+	// Muted sounds are not played at all
+	select {
+	case <-engine.Play(sound, 0):
+	default:
+		t.Error("Expected a muted sound to be done at once")
+	}
+	if len(factory.players) != 1 {
+		t.Errorf("Expected no player for a muted sound, got %d players", len(factory.players))
+	}
+	engine.Stop()
+}
@@ -0,0 +1,69 @@
+package audio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/d-mozulyov/vox/internal/wav"
+)
+
+// Sound is decoded audio in the format of the engine (SampleRate, stereo, 16-bit little-endian)
+type Sound struct {
+	pcm []byte
+}
+
+// Duration returns the playing time of the sound
+func (s *Sound) Duration() time.Duration {
+	frames := len(s.pcm) / (channelCount * 2)
+	return time.Duration(frames) * time.Second / SampleRate
+}
+
+// DecodeWAV decodes a 16-bit PCM WAV file, mono or stereo, at any sample rate
+// and converts it to the format of the engine
+func DecodeWAV(data []byte) (*Sound, error) {
+	samples, format, err := wav.Read(data)
+	if err != nil {
+		return nil, err
+	}
+	if format.Channels != 1 && format.Channels != 2 {
+		return nil, fmt.Errorf("unsupported WAV channel count %d", format.Channels)
+	}
+
+	frames := len(samples) / format.Channels
+	left := make([]int16, frames)
+	right := make([]int16, frames)
+	for i := 0; i < frames; i++ {
+		left[i] = samples[i*format.Channels]
+		right[i] = samples[i*format.Channels+format.Channels-1]
+	}
+	left = resample(left, format.SampleRate, SampleRate)
+	right = resample(right, format.SampleRate, SampleRate)
+
+	out := make([]byte, len(left)*channelCount*2)
+	for i := range left {
+		binary.LittleEndian.PutUint16(out[i*4:], uint16(left[i]))
+		binary.LittleEndian.PutUint16(out[i*4+2:], uint16(right[i]))
+	}
+	return &Sound{pcm: out}, nil
+}
+
+// resample converts samples between rates by linear interpolation
+// Good enough for short feedback sounds
+func resample(samples []int16, from, to int) []int16 {
+	if from == to || len(samples) == 0 {
+		return samples
+	}
+	out := make([]int16, int(int64(len(samples))*int64(to)/int64(from)))
+	for i := range out {
+		position := float64(i) * float64(from) / float64(to)
+		index := int(position)
+		if index+1 >= len(samples) {
+			out[i] = samples[len(samples)-1]
+			continue
+		}
+		fraction := position - float64(index)
+		out[i] = int16(float64(samples[index])*(1-fraction) + float64(samples[index+1])*fraction)
+	}
+	return out
+}
@@ -0,0 +1,116 @@
+// Package dsp provides streaming audio filters applied to captured microphone audio.
+//
+// Filters work on consecutive frames of normalized samples (-1.0 to 1.0) and keep
+// their state between calls, so splitting a signal into frames of any size gives
+// the same result as processing it at once. This lets the chain run inside the
+// capture callback, before voice detection or streaming upload see the audio.
+package dsp
+
+import (
+	"math"
+
+	"github.com/d-mozulyov/vox/pkg/config"
+)
+
+// Filter defines the interface for a streaming audio filter
+type Filter interface {
+	// Process filters a frame of normalized samples in place
+	Process(samples []float64)
+
+	// Reset clears the filter state before an unrelated stream
+	Reset()
+}
+
+// Chain applies filters in order to 16-bit PCM frames
+type Chain struct {
+	filters []Filter
+	buffer  []float64
+}
+
+// NewChain creates a chain of the given filters, applied in order
+func NewChain(filters ...Filter) *Chain {
+	return &Chain{
+		filters: filters,
+	}
+}
+
+// NewChainFromConfig creates the built-in filter chain enabled in the audio configuration
+// Order: high-pass, noise gate, automatic gain control, limiter
+func NewChainFromConfig(cfg config.AudioConfig, sampleRate int) *Chain {
+	var filters []Filter
+
+	if cfg.HighPass.Enabled {
+		filters = append(filters, NewHighPass(cfg.HighPass.CutoffHz, sampleRate))
+	}
+	if cfg.NoiseGate.Enabled {
+		filters = append(filters, NewNoiseGate(cfg.NoiseGate.ThresholdDB, cfg.NoiseGate.HoldMs, sampleRate))
+	}
+	if cfg.AGC.Enabled {
+		filters = append(filters, NewAGC(cfg.AGC.TargetDB, cfg.AGC.MaxGainDB, sampleRate))
+	}
+	if cfg.Limiter.Enabled {
+		filters = append(filters, NewLimiter(cfg.Limiter.CeilingDB, sampleRate))
+	}
+
+	return NewChain(filters...)
+}
+
+// Len returns the number of filters in the chain
+func (c *Chain) Len() int {
+	return len(c.filters)
+}
+
+// Process filters a frame of 16-bit PCM samples in place
+// Results outside the 16-bit range are clipped
+func (c *Chain) Process(samples []int16) {
+	if len(c.filters) == 0 {
+		return
+	}
+
+	if cap(c.buffer) < len(samples) {
+		c.buffer = make([]float64, len(samples))
+	}
+	buffer := c.buffer[:len(samples)]
+
+	for i, s := range samples {
+		buffer[i] = float64(s) / 32768
+	}
+
+	for _, filter := range c.filters {
+		filter.Process(buffer)
+	}
+
+	for i, v := range buffer {
+		samples[i] = toInt16(v)
+	}
+}
+
+// Reset clears the state of all filters
+func (c *Chain) Reset() {
+	for _, filter := range c.filters {
+		filter.Reset()
+	}
+}
+
+// toInt16 converts a normalized sample to 16-bit PCM with clipping
+func toInt16(v float64) int16 {
+	v = math.Round(v * 32768)
+	if v > math.MaxInt16 {
+		return math.MaxInt16
+	}
+	if v < math.MinInt16 {
+		return math.MinInt16
+	}
+	return int16(v)
+}
+
+// dbToGain converts decibels to a linear gain factor
+func dbToGain(db float64) float64 {
+	return math.Pow(10, db/20)
+}
+
+// timeCoef returns the smoothing coefficient of a one-pole filter
+// that reaches ~63% of a step within the given time
+func timeCoef(ms float64, sampleRate int) float64 {
+	return math.Exp(-1000 / (ms * float64(sampleRate)))
+}
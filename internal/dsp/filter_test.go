@@ -0,0 +1,154 @@
+package dsp
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/d-mozulyov/vox/pkg/config"
+)
+
+const testSampleRate = 16000
+
+// sine generates a sine wave with the given frequency and amplitude in dBFS
+func sine(freq, amplitudeDB float64, seconds float64) []float64 {
+	amplitude := dbToGain(amplitudeDB)
+	samples := make([]float64, int(seconds*testSampleRate))
+	for i := range samples {
+		samples[i] = amplitude * math.Sin(2*math.Pi*freq*float64(i)/testSampleRate)
+	}
+	return samples
+}
+
+// rmsDB returns the RMS level in dBFS of the last half of the samples (after filters settle)
+func rmsDB(samples []float64) float64 {
+	tail := samples[len(samples)/2:]
+	var sum float64
+	for _, v := range tail {
+		sum += v * v
+	}
+	return 20 * math.Log10(math.Sqrt(sum/float64(len(tail))))
+}
+
+// TestHighPass tests that rumble is removed and speech frequencies pass
+func TestHighPass(t *testing.T) {
+	rumble := sine(20, -6, 1)
+	NewHighPass(80, testSampleRate).Process(rumble)
+	if db := rmsDB(rumble); db > -25 {
+		t.Errorf("Expected 20 Hz to be attenuated below -25 dBFS, got %.1f", db)
+	}
+
+	voice := sine(1000, -6, 1)
+	NewHighPass(80, testSampleRate).Process(voice)
+	if db := rmsDB(voice); math.Abs(db-(-9)) > 0.5 {
+		t.Errorf("Expected 1 kHz to pass at -9 dBFS RMS, got %.1f", db)
+	}
+}
+
+// TestNoiseGate tests that background noise is silenced and speech passes
+func TestNoiseGate(t *testing.T) {
+	noise := sine(300, -70, 1)
+	NewNoiseGate(-50, 200, testSampleRate).Process(noise)
+	if db := rmsDB(noise); db > -100 {
+		t.Errorf("Expected noise below threshold to be gated, got %.1f dBFS", db)
+	}
+
+	voice := sine(300, -20, 1)
+	NewNoiseGate(-50, 200, testSampleRate).Process(voice)
+	if db := rmsDB(voice); math.Abs(db-(-23)) > 0.5 {
+		t.Errorf("Expected signal above threshold to pass at -23 dBFS RMS, got %.1f", db)
+	}
+}
+
+// TestAGC tests that quiet and loud signals are brought towards the target level
+func TestAGC(t *testing.T) {
+	quiet := sine(300, -40, 4)
+	NewAGC(-20, 30, testSampleRate).Process(quiet)
+	if db := rmsDB(quiet); math.Abs(db-(-20)) > 1 {
+		t.Errorf("Expected quiet signal raised to -20 dBFS, got %.1f", db)
+	}
+
+	loud := sine(300, -3, 4)
+	NewAGC(-20, 30, testSampleRate).Process(loud)
+	if db := rmsDB(loud); math.Abs(db-(-20)) > 1 {
+		t.Errorf("Expected loud signal lowered to -20 dBFS, got %.1f", db)
+	}
+
+	// Gain is capped by maxGain
+	faint := sine(300, -50, 4)
+	NewAGC(-20, 10, testSampleRate).Process(faint)
+	if db := rmsDB(faint); math.Abs(db-(-43)) > 1 {
+		t.Errorf("Expected gain capped at 10 dB (-43 dBFS), got %.1f", db)
+	}
+}
+
+// TestLimiter tests that peaks never exceed the ceiling
+func TestLimiter(t *testing.T) {
+	signal := sine(300, 0, 1)
+	NewLimiter(-6, testSampleRate).Process(signal)
+
+	ceiling := dbToGain(-6)
+	for i, v := range signal {
+		if math.Abs(v) > ceiling+1e-9 {
+			t.Fatalf("Sample %d exceeds ceiling: %f > %f", i, math.Abs(v), ceiling)
+		}
+	}
+}
+
+// TestChainStreaming tests that frame boundaries don't change the result
+func TestChainStreaming(t *testing.T) {
+	cfg := config.Default().Audio
+	cfg.NoiseGate.Enabled = true
+	cfg.AGC.Enabled = true
+
+	signal := make([]int16, 2*testSampleRate)
+	for i := range signal {
+		// Rumble + voice with a pause in the middle
+		v := 0.3*math.Sin(2*math.Pi*30*float64(i)/testSampleRate) +
+			0.2*math.Sin(2*math.Pi*440*float64(i)/testSampleRate)
+		if i > testSampleRate/2 && i < testSampleRate {
+			v *= 0.001
+		}
+		signal[i] = toInt16(v)
+	}
+
+	whole := slices.Clone(signal)
+	chain := NewChainFromConfig(cfg, testSampleRate)
+	if chain.Len() != 4 {
+		t.Fatalf("Expected 4 filters, got %d", chain.Len())
+	}
+	chain.Process(whole)
+
+	framed := slices.Clone(signal)
+	chain = NewChainFromConfig(cfg, testSampleRate)
+	for start, size := 0, 1; start < len(framed); start, size = start+size, size%997+160 {
+		end := min(start+size, len(framed))
+		chain.Process(framed[start:end])
+	}
+
+	if !slices.Equal(whole, framed) {
+		t.Error("Expected identical output for whole and framed processing")
+	}
+}
+
+// gainFilter multiplies samples by a constant, used to test clipping
+type gainFilter float64
+
+func (g gainFilter) Process(samples []float64) {
+	for i := range samples {
+		samples[i] *= float64(g)
+	}
+}
+
+func (g gainFilter) Reset() {}
+
+// TestChainClipping tests conversion back to 16-bit PCM
+func TestChainClipping(t *testing.T) {
+	samples := []int16{20000, -20000, 100}
+	NewChain(gainFilter(2)).Process(samples)
+
+	expected := []int16{math.MaxInt16, math.MinInt16, 200}
+	if !slices.Equal(samples, expected) {
+		t.Errorf("Expected %v, got %v", expected, samples)
+	}
+}
@@ -0,0 +1,184 @@
+package dsp
+
+import "math"
+
+// HighPass is a second-order Butterworth high-pass filter that removes
+// low-frequency rumble (desk bumps, fans, handling noise)
+type HighPass struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     float64
+}
+
+// NewHighPass creates a high-pass filter with the given cutoff frequency
+func NewHighPass(cutoffHz float64, sampleRate int) *HighPass {
+	// RBJ Audio EQ Cookbook coefficients, Q = 1/sqrt(2)
+	w0 := 2 * math.Pi * cutoffHz / float64(sampleRate)
+	alpha := math.Sin(w0) / math.Sqrt2
+	cos := math.Cos(w0)
+	a0 := 1 + alpha
+
+	return &HighPass{
+		b0: (1 + cos) / 2 / a0,
+		b1: -(1 + cos) / a0,
+		b2: (1 + cos) / 2 / a0,
+		a1: -2 * cos / a0,
+		a2: (1 - alpha) / a0,
+	}
+}
+
+// Process filters a frame in place
+func (f *HighPass) Process(samples []float64) {
+	for i, x := range samples {
+		y := f.b0*x + f.b1*f.x1 + f.b2*f.x2 - f.a1*f.y1 - f.a2*f.y2
+		f.x2, f.x1 = f.x1, x
+		f.y2, f.y1 = f.y1, y
+		samples[i] = y
+	}
+}
+
+// Reset clears the filter state
+func (f *HighPass) Reset() {
+	f.x1, f.x2, f.y1, f.y2 = 0, 0, 0, 0
+}
+
+// NoiseGate silences the signal while its level stays below a threshold
+// The gate stays open for a hold time after the level drops, so word endings are kept
+type NoiseGate struct {
+	threshold   float64
+	holdSamples int
+
+	envelopeCoef float64 // envelope release
+	openCoef     float64 // gain smoothing when opening
+	closeCoef    float64 // gain smoothing when closing
+
+	envelope float64
+	hold     int
+	gain     float64
+}
+
+// NewNoiseGate creates a noise gate with the threshold in dBFS and the hold time in milliseconds
+func NewNoiseGate(thresholdDB float64, holdMs int, sampleRate int) *NoiseGate {
+	return &NoiseGate{
+		threshold:    dbToGain(thresholdDB),
+		holdSamples:  holdMs * sampleRate / 1000,
+		envelopeCoef: timeCoef(10, sampleRate),
+		openCoef:     timeCoef(1, sampleRate),
+		closeCoef:    timeCoef(20, sampleRate),
+	}
+}
+
+// Process filters a frame in place
+func (f *NoiseGate) Process(samples []float64) {
+	for i, x := range samples {
+		f.envelope = math.Max(math.Abs(x), f.envelope*f.envelopeCoef)
+		if f.envelope >= f.threshold {
+			f.hold = f.holdSamples
+		}
+
+		target := 0.0
+		if f.hold > 0 {
+			target = 1
+			f.hold--
+		}
+
+		coef := f.closeCoef
+		if target > f.gain {
+			coef = f.openCoef
+		}
+		f.gain = target + (f.gain-target)*coef
+
+		samples[i] = x * f.gain
+	}
+}
+
+// Reset clears the filter state (the gate starts closed)
+func (f *NoiseGate) Reset() {
+	f.envelope, f.hold, f.gain = 0, 0, 0
+}
+
+// agcNoiseFloor is the RMS level below which AGC stops raising the gain,
+// so that pauses are not amplified into noise
+var agcNoiseFloor = dbToGain(-55)
+
+// AGC (automatic gain control) brings the average speech level towards a target
+// Gain is reduced quickly on loud input and raised slowly on quiet input
+type AGC struct {
+	target  float64
+	maxGain float64
+
+	powerCoef   float64 // RMS averaging window
+	attackCoef  float64 // gain decrease
+	releaseCoef float64 // gain increase
+
+	power float64
+	gain  float64
+}
+
+// NewAGC creates an AGC with the target RMS level in dBFS and the maximum gain in dB
+func NewAGC(targetDB, maxGainDB float64, sampleRate int) *AGC {
+	return &AGC{
+		target:      dbToGain(targetDB),
+		maxGain:     dbToGain(maxGainDB),
+		powerCoef:   timeCoef(300, sampleRate),
+		attackCoef:  timeCoef(50, sampleRate),
+		releaseCoef: timeCoef(500, sampleRate),
+		gain:        1,
+	}
+}
+
+// Process filters a frame in place
+func (f *AGC) Process(samples []float64) {
+	for i, x := range samples {
+		f.power = f.powerCoef*f.power + (1-f.powerCoef)*x*x
+		rms := math.Sqrt(f.power)
+
+		desired := f.gain
+		if rms > agcNoiseFloor {
+			desired = math.Min(f.target/rms, f.maxGain)
+		}
+
+		coef := f.releaseCoef
+		if desired < f.gain {
+			coef = f.attackCoef
+		}
+		f.gain = desired + (f.gain-desired)*coef
+
+		samples[i] = x * f.gain
+	}
+}
+
+// Reset clears the filter state (unity gain)
+func (f *AGC) Reset() {
+	f.power, f.gain = 0, 1
+}
+
+// Limiter keeps peaks below a ceiling to prevent clipping
+// Attack is instant, so the output never exceeds the ceiling
+type Limiter struct {
+	ceiling     float64
+	releaseCoef float64
+	envelope    float64
+}
+
+// NewLimiter creates a limiter with the ceiling in dBFS
+func NewLimiter(ceilingDB float64, sampleRate int) *Limiter {
+	return &Limiter{
+		ceiling:     dbToGain(ceilingDB),
+		releaseCoef: timeCoef(50, sampleRate),
+	}
+}
+
+// Process filters a frame in place
+func (f *Limiter) Process(samples []float64) {
+	for i, x := range samples {
+		f.envelope = math.Max(math.Abs(x), f.envelope*f.releaseCoef)
+		if f.envelope > f.ceiling {
+			samples[i] = x * f.ceiling / f.envelope
+		}
+	}
+}
+
+// Reset clears the filter state
+func (f *Limiter) Reset() {
+	f.envelope = 0
+}
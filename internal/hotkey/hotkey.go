@@ -80,6 +80,20 @@ const (
 	KeyZ
 )
 
+// ParseKey converts a key name from the configuration ("A" to "Z") to a Key
+func ParseKey(name string) (Key, error) {
+	if len(name) == 1 {
+		c := name[0]
+		if c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		if c >= 'A' && c <= 'Z' {
+			return Key(c - 'A'), nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported key: %q", name)
+}
+
 // Hotkey represents a global hotkey combination
 type Hotkey struct {
 	Modifiers []Modifier
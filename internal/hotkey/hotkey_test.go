@@ -0,0 +1,44 @@
+package hotkey
+
+import "testing"
+
+// TestParseKey tests that key names are parsed case-insensitively and that other names are rejected
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected Key
+		valid    bool
+	}{
+		{"A", KeyA, true},
+		{"a", KeyA, true},
+		{"V", KeyV, true},
+		{"v", KeyV, true},
+		{"Z", KeyZ, true},
+		{"z", KeyZ, true},
+		{"", 0, false},
+		{"AB", 0, false},
+		{"F1", 0, false},
+		{"Space", 0, false},
+		{"1", 0, false},
+		{"@", 0, false},
+		{"[", 0, false},
+		{"`", 0, false},
+		{"{", 0, false},
+		{" a", 0, false},
+		{"é", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseKey(tt.name)
+		if !tt.valid {
+			if err == nil {
+				t.Errorf("ParseKey(%q): expected an error, got %v", tt.name, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseKey(%q) failed: %v", tt.name, err)
+		} else if got != tt.expected {
+			t.Errorf("ParseKey(%q) = %v, expected %v", tt.name, got, tt.expected)
+		}
+	}
+}
@@ -1,168 +1,131 @@
 package indicator
 
 import (
-	"bytes"
-	"encoding/binary"
 	"fmt"
-	"os"
-	"path/filepath"
-	"time"
+	"io/fs"
+	"math"
+	"sync"
 
+	"github.com/d-mozulyov/vox/internal/audio"
 	"github.com/d-mozulyov/vox/internal/platform"
-	"github.com/d-mozulyov/vox/internal/state"
-	"github.com/ebitengine/oto/v3"
 )
 
 // AudioIndicator defines the interface for audio state indication
 type AudioIndicator interface {
-	// PlaySound plays an audio feedback for state transition
-	PlaySound(from, to state.State) error
+	// PlaySound starts the sound of an event in the selected theme and returns at once
+	// The returned channel is closed when the sound has finished (at once if nothing plays)
+	PlaySound(event SoundEvent) <-chan struct{}
+
+	// SetTheme selects the sound theme
+	SetTheme(theme Theme)
+
+	// SetVolume sets the global volume from 0 (silent) to 1, applied on top of the theme's gains
+	SetVolume(volume float64)
 }
 
+// finished is returned for sounds that are not played
+var finished = func() chan struct{} {
+	done := make(chan struct{})
+	close(done)
+	return done
+}()
+
 // audioIndicator implements the AudioIndicator interface
 type audioIndicator struct {
-	soundsPath string
-	context    *oto.Context
-}
+	builtin Theme // provides the sounds missing from the selected theme
+	engine  *audio.Engine
 
-// NewAudioIndicator creates a new audio indicator instance
-// soundsPath is the directory containing sound files
-func NewAudioIndicator(soundsPath string) (AudioIndicator, error) {
-	logger := platform.GetLogger()
-
-	// Initialize oto context with standard settings
-	// 44100 Hz sample rate, 2 channels (stereo), 16-bit samples
-	op := &oto.NewContextOptions{
-		SampleRate:   44100,
-		ChannelCount: 2,
-		Format:       oto.FormatSignedInt16LE,
-	}
+	mutex  sync.RWMutex
+	theme  Theme
+	volume float64
+	sounds map[string]*audio.Sound // decoded sounds by soundKey
+}
 
-	ctx, readyChan, err := oto.NewContext(op)
+// NewAudioIndicator creates a new audio indicator instance playing the built-in theme
+// volume is the global volume from 0 to 1; policy decides whether a new sound
+// interrupts the one playing. The built-in sounds are decoded here, once
+func NewAudioIndicator(builtin Theme, volume float64, policy audio.Policy) (AudioIndicator, error) {
+	engine, err := audio.NewEngine(policy)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize audio context: %w", err)
+		return nil, err
 	}
 
-	// Wait for the audio context to be ready
-	<-readyChan
+	ai := &audioIndicator{
+		engine: engine,
+		volume: clampVolume(volume),
+		sounds: make(map[string]*audio.Sound),
+	}
+	ai.builtin = ai.load(builtin)
+	ai.theme = ai.builtin
 
-	logger.Info("Audio indicator created successfully, sounds path: %s", soundsPath)
+	platform.GetLogger().Info("Audio indicator created successfully, built-in sounds: %d", len(ai.builtin.Sounds))
+	return ai, nil
+}
 
-	return &audioIndicator{
-		soundsPath: soundsPath,
-		context:    ctx,
-	}, nil
+// SetTheme selects the sound theme, decoding its sounds if it is selected for the first time
+func (ai *audioIndicator) SetTheme(theme Theme) {
+	ai.mutex.Lock()
+	defer ai.mutex.Unlock()
+	ai.theme = ai.load(theme)
+	platform.GetLogger().Info("Sound theme selected: %s", theme.Name)
 }
 
-// PlaySound plays an audio feedback for the given state transition
-func (ai *audioIndicator) PlaySound(from, to state.State) error {
+// load decodes the sounds of a theme that are not decoded yet
+// Returns the theme without the sounds that failed to decode, so the built-in ones play instead
+// Must be called with the mutex held, or before the indicator is shared
+func (ai *audioIndicator) load(theme Theme) Theme {
 	logger := platform.GetLogger()
-
-	// Determine which sound file to play
-	filename := ai.getSoundFilename(from, to)
-	if filename == "" {
-		// No sound for this transition
-		return nil
-	}
-
-	// Load and play the sound
-	soundPath := filepath.Join(ai.soundsPath, filename)
-	if err := ai.playWavFile(soundPath); err != nil {
-		// Log warning but don't return error - audio is non-critical
-		logger.Warn("Failed to play audio feedback: %v", err)
+	sounds := make(map[SoundEvent]string, len(theme.Sounds))
+	for event, file := range theme.Sounds {
+		key := soundKey(theme, file)
+		if _, ok := ai.sounds[key]; !ok {
+			sound, err := decodeFile(theme.Files, file)
+			if err != nil {
+				logger.Warn("Theme %s: failed to load the sound of %s: %v", theme.Name, event, err)
+				continue
+			}
+			ai.sounds[key] = sound
+		}
+		sounds[event] = file
 	}
-
-	return nil
+	theme.Sounds = sounds
+	return theme
 }
 
-// getSoundFilename returns the sound filename for a state transition
-func (ai *audioIndicator) getSoundFilename(from, to state.State) string {
-	// Play start_recording.wav when starting recording (Idle -> Recording)
-	if from == state.StateIdle && to == state.StateRecording {
-		return "start_recording.wav"
-	}
-	// Play stop_recording.wav when stopping recording (Recording -> Idle)
-	if from == state.StateRecording && to == state.StateIdle {
-		return "stop_recording.wav"
+// decodeFile reads and decodes a WAV file
+func decodeFile(files fs.FS, name string) (*audio.Sound, error) {
+	data, err := fs.ReadFile(files, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
-	return ""
+	return audio.DecodeWAV(data)
 }
 
-// wavHeader represents a WAV file header
-type wavHeader struct {
-	ChunkID       [4]byte
-	ChunkSize     uint32
-	Format        [4]byte
-	Subchunk1ID   [4]byte
-	Subchunk1Size uint32
-	AudioFormat   uint16
-	NumChannels   uint16
-	SampleRate    uint32
-	ByteRate      uint32
-	BlockAlign    uint16
-	BitsPerSample uint16
-	Subchunk2ID   [4]byte
-	Subchunk2Size uint32
+// SetVolume sets the global volume
+func (ai *audioIndicator) SetVolume(volume float64) {
+	ai.mutex.Lock()
+	defer ai.mutex.Unlock()
+	ai.volume = clampVolume(volume)
+	platform.GetLogger().Info("Sound volume set to %.0f%%", ai.volume*100)
 }
 
-// playWavFile loads and plays a WAV file
-func (ai *audioIndicator) playWavFile(path string) error {
-	logger := platform.GetLogger()
-
-	// Read the entire WAV file
-	data, err := os.ReadFile(path)
-	if err != nil {
-		logger.Error("Failed to read audio file %s: %v", path, err)
-		return fmt.Errorf("failed to read file: %w", err)
-	}
-
-	// Parse WAV header
-	if len(data) < 44 {
-		return fmt.Errorf("file too small to be a valid WAV file")
-	}
-
-	reader := bytes.NewReader(data)
-	var header wavHeader
-	if err := binary.Read(reader, binary.LittleEndian, &header); err != nil {
-		return fmt.Errorf("failed to parse WAV header: %w", err)
-	}
-
-	// Validate WAV format
-	if string(header.ChunkID[:]) != "RIFF" || string(header.Format[:]) != "WAVE" {
-		return fmt.Errorf("not a valid WAV file")
-	}
-
-	// Get audio data (skip header)
-	audioData := data[44:]
-
-	// Convert to stereo if mono
-	if header.NumChannels == 1 && header.BitsPerSample == 16 {
-		audioData = ai.monoToStereo(audioData)
-	}
-
-	// Create a player and play the sound
-	player := ai.context.NewPlayer(bytes.NewReader(audioData))
-	player.Play()
-
-	// Calculate duration and wait for playback to complete
-	duration := time.Duration(float64(len(audioData)) / float64(header.ByteRate) * float64(time.Second))
-	time.Sleep(duration)
-
-	logger.Info("Audio feedback played successfully: %s", path)
-
-	return nil
+// clampVolume limits the global volume to 0..1
+func clampVolume(volume float64) float64 {
+	return math.Max(0, math.Min(1, volume))
 }
 
-// monoToStereo converts mono 16-bit PCM to stereo by duplicating each sample
-func (ai *audioIndicator) monoToStereo(mono []byte) []byte {
-	stereo := make([]byte, len(mono)*2)
-	for i := 0; i < len(mono); i += 2 {
-		// Copy left channel
-		stereo[i*2] = mono[i]
-		stereo[i*2+1] = mono[i+1]
-		// Copy right channel (same as left)
-		stereo[i*2+2] = mono[i]
-		stereo[i*2+3] = mono[i+1]
+// PlaySound starts the sound of an event in the selected theme
+// Events the theme has no sound for play the built-in sound
+func (ai *audioIndicator) PlaySound(event SoundEvent) <-chan struct{} {
+	ai.mutex.RLock()
+	key, gain := ai.theme.sound(event, ai.builtin)
+	sound := ai.sounds[key]
+	gain *= ai.volume
+	ai.mutex.RUnlock()
+
+	if sound == nil || gain == 0 {
+		// No sound for this event, or muted
+		return finished
 	}
-	return stereo
+	return ai.engine.Play(sound, gain)
 }
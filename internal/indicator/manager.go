@@ -10,20 +10,33 @@ import (
 // IndicatorManager defines the interface for coordinating visual and audio indicators
 type IndicatorManager interface {
 	// OnStateChange handles state transitions and triggers indicators
-	OnStateChange(oldState, newState state.State)
+	OnStateChange(change state.Change)
+
+	// OnCancel signals that a recording or transcription was cancelled
+	OnCancel()
+
+	// OnLevelChange handles microphone input level updates (0.0 to 1.0)
+	OnLevelChange(level float64)
+
+	// Notify shows a desktop notification, e.g. where a queued transcript went
+	Notify(notification Notification)
 
 	// SetVisualIndicator sets the visual indicator implementation
 	SetVisualIndicator(indicator VisualIndicator)
 
 	// SetAudioIndicator sets the audio indicator implementation
 	SetAudioIndicator(indicator AudioIndicator)
+
+	// SetNotificationIndicator sets the desktop notification implementation
+	SetNotificationIndicator(indicator NotificationIndicator)
 }
 
 // indicatorManager implements the IndicatorManager interface
 type indicatorManager struct {
-	visualIndicator VisualIndicator
-	audioIndicator  AudioIndicator
-	mutex           sync.RWMutex
+	visualIndicator       VisualIndicator
+	audioIndicator        AudioIndicator
+	notificationIndicator NotificationIndicator
+	mutex                 sync.RWMutex
 }
 
 // NewIndicatorManager creates a new indicator manager instance
@@ -45,9 +58,18 @@ func (im *indicatorManager) SetAudioIndicator(indicator AudioIndicator) {
 	im.audioIndicator = indicator
 }
 
+// SetNotificationIndicator sets the desktop notification implementation
+func (im *indicatorManager) SetNotificationIndicator(indicator NotificationIndicator) {
+	im.mutex.Lock()
+	defer im.mutex.Unlock()
+	im.notificationIndicator = indicator
+}
+
 // OnStateChange handles state transitions by coordinating visual and audio indicators
 // Both indicators are triggered in parallel for responsiveness
-func (im *indicatorManager) OnStateChange(oldState, newState state.State) {
+// Sounds play in the background, except the start sound: it is waited for,
+// so a recorder started after OnStateChange returns does not capture it
+func (im *indicatorManager) OnStateChange(change state.Change) {
 	im.mutex.RLock()
 	visual := im.visualIndicator
 	audio := im.audioIndicator
@@ -55,6 +77,12 @@ func (im *indicatorManager) OnStateChange(oldState, newState state.State) {
 
 	logger := platform.GetLogger()
 
+	// A failure is also reported by a notification: the error icon is easy to miss
+	// A queued recording is reported by its owner, together with the retry
+	if change.To == state.StateError && (change.Session == nil || !change.Session.Queued()) {
+		im.Notify(errorNotification(change))
+	}
+
 	// Use WaitGroup to execute indicators in parallel
 	var wg sync.WaitGroup
 
@@ -63,19 +91,20 @@ func (im *indicatorManager) OnStateChange(oldState, newState state.State) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			if err := visual.UpdateIcon(newState); err != nil {
-				logger.Warn("Failed to update visual indicator: %v", err)
+			if err := visual.UpdateIcon(change.To); err != nil {
+				logger.Warn("Failed to update visual indicator%s: %v", sessionSuffix(change), err)
 			}
 		}()
 	}
 
 	// Trigger audio indicator
-	if audio != nil {
+	if event, ok := soundEvent(change); ok && audio != nil {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			if err := audio.PlaySound(oldState, newState); err != nil {
-				logger.Warn("Failed to play audio indicator: %v", err)
+			done := audio.PlaySound(event)
+			if event == SoundStart {
+				<-done
 			}
 		}()
 	}
@@ -83,3 +112,91 @@ func (im *indicatorManager) OnStateChange(oldState, newState state.State) {
 	// Wait for both indicators to complete
 	wg.Wait()
 }
+
+// soundEvent returns the sound event of a state change, if it has one
+// Returning to Idle plays a sound only when a transcript was inserted:
+// a cancellation plays its own sound through OnCancel
+func soundEvent(change state.Change) (SoundEvent, bool) {
+	switch {
+	case change.To == state.StateRecording:
+		return SoundStart, true
+	case change.From == state.StateRecording && change.To == state.StateTranscribing:
+		return SoundStop, true
+	case change.To == state.StateError:
+		return SoundError, true
+	case change.From == state.StateTranscribing && change.To == state.StateIdle && change.Session != nil:
+		if _, transcript, err := change.Session.Result(); err == nil && transcript != "" {
+			return SoundInserted, true
+		}
+	}
+	return "", false
+}
+
+// errorNotification describes the failure that led to the Error state
+func errorNotification(change state.Change) Notification {
+	notification := Notification{
+		Summary: "Transcription failed",
+		Urgency: UrgencyCritical,
+	}
+	if change.Event == state.EventTimeout {
+		notification.Body = "The transcription took too long and was cancelled"
+	}
+	if change.Session != nil {
+		if _, _, err := change.Session.Result(); err != nil {
+			notification.Body = err.Error()
+		}
+	}
+	return notification
+}
+
+// sessionSuffix names the session of a change in log messages
+func sessionSuffix(change state.Change) string {
+	if change.Session == nil {
+		return ""
+	}
+	return " (session " + change.Session.ID + ")"
+}
+
+// OnCancel plays the cancel sound
+// The icon is already updated by the transition back to Idle
+func (im *indicatorManager) OnCancel() {
+	im.mutex.RLock()
+	audio := im.audioIndicator
+	im.mutex.RUnlock()
+
+	if audio == nil {
+		return
+	}
+
+	audio.PlaySound(SoundCancel)
+}
+
+// Notify forwards a notification to the notification indicator
+func (im *indicatorManager) Notify(notification Notification) {
+	im.mutex.RLock()
+	notifier := im.notificationIndicator
+	im.mutex.RUnlock()
+
+	if notifier == nil {
+		return
+	}
+
+	if err := notifier.Notify(notification); err != nil {
+		platform.GetLogger().Warn("Failed to show notification: %v", err)
+	}
+}
+
+// OnLevelChange forwards the microphone input level to the visual indicator
+func (im *indicatorManager) OnLevelChange(level float64) {
+	im.mutex.RLock()
+	visual := im.visualIndicator
+	im.mutex.RUnlock()
+
+	if visual == nil {
+		return
+	}
+
+	if err := visual.UpdateLevel(level); err != nil {
+		platform.GetLogger().Warn("Failed to update input level indicator: %v", err)
+	}
+}
@@ -1,14 +1,19 @@
 package indicator
 
 import (
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/d-mozulyov/vox/internal/session"
 	"github.com/d-mozulyov/vox/internal/state"
 )
 
 // mockVisualIndicator is a mock implementation of VisualIndicator for testing
 type mockVisualIndicator struct {
-	callCount int
+	callCount  int
+	levelCount int
 }
 
 func (m *mockVisualIndicator) UpdateIcon(s state.State) error {
@@ -16,16 +21,25 @@ func (m *mockVisualIndicator) UpdateIcon(s state.State) error {
 	return nil
 }
 
+func (m *mockVisualIndicator) UpdateLevel(level float64) error {
+	m.levelCount++
+	return nil
+}
+
 // mockAudioIndicator is a mock implementation of AudioIndicator for testing
 type mockAudioIndicator struct {
-	callCount int
+	events []SoundEvent
 }
 
-func (m *mockAudioIndicator) PlaySound(from, to state.State) error {
-	m.callCount++
-	return nil
+func (m *mockAudioIndicator) PlaySound(event SoundEvent) <-chan struct{} {
+	m.events = append(m.events, event)
+	return finished
 }
 
+func (m *mockAudioIndicator) SetTheme(theme Theme) {}
+
+func (m *mockAudioIndicator) SetVolume(volume float64) {}
+
 // TestIndicatorManager_OnStateChange tests basic coordination of indicators
 func TestIndicatorManager_OnStateChange(t *testing.T) {
 	manager := NewIndicatorManager()
@@ -37,14 +51,94 @@ func TestIndicatorManager_OnStateChange(t *testing.T) {
 	manager.SetAudioIndicator(audioMock)
 
 	// Trigger state change
-	manager.OnStateChange(state.StateIdle, state.StateRecording)
+	manager.OnStateChange(state.Change{Seq: 1, From: state.StateIdle, To: state.StateRecording})
 
 	// Verify both indicators were called
 	if visualMock.callCount != 1 {
 		t.Errorf("Expected visual indicator to be called once, got %d", visualMock.callCount)
 	}
-	if audioMock.callCount != 1 {
-		t.Errorf("Expected audio indicator to be called once, got %d", audioMock.callCount)
+	if len(audioMock.events) != 1 || audioMock.events[0] != SoundStart {
+		t.Errorf("Expected the start sound, got %v", audioMock.events)
+	}
+}
+
+// TestIndicatorManager_SoundEvents tests which state changes play which sounds
+func TestIndicatorManager_SoundEvents(t *testing.T) {
+	inserted := session.New(time.Now())
+	inserted.SetResult("chat", "hello", nil)
+	cancelled := session.New(time.Now())
+	cancelled.SetResult("", "", errors.New("cancelled"))
+
+	tests := []struct {
+		name   string
+		change state.Change
+		want   SoundEvent // empty for no sound
+	}{
+		{"start", state.Change{From: state.StateIdle, To: state.StateRecording}, SoundStart},
+		{"restart after error", state.Change{From: state.StateError, To: state.StateRecording}, SoundStart},
+		{"stop", state.Change{From: state.StateRecording, To: state.StateTranscribing}, SoundStop},
+		{"recording cancelled", state.Change{From: state.StateRecording, To: state.StateIdle}, ""},
+		{"failed", state.Change{From: state.StateTranscribing, To: state.StateError}, SoundError},
+		{"inserted", state.Change{From: state.StateTranscribing, To: state.StateIdle, Session: inserted}, SoundInserted},
+		{"transcription cancelled", state.Change{From: state.StateTranscribing, To: state.StateIdle, Session: cancelled}, ""},
+		{"error dismissed", state.Change{From: state.StateError, To: state.StateIdle}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := NewIndicatorManager()
+			audioMock := &mockAudioIndicator{}
+			manager.SetAudioIndicator(audioMock)
+			manager.OnStateChange(tt.change)
+
+			var got SoundEvent
+			if len(audioMock.events) > 0 {
+				got = audioMock.events[0]
+			}
+			if len(audioMock.events) > 1 || got != tt.want {
+				t.Errorf("Expected %q, got %v", tt.want, audioMock.events)
+			}
+		})
+	}
+}
+
+// blockingAudioIndicator plays sounds until released
+type blockingAudioIndicator struct {
+	mockAudioIndicator
+	release chan struct{}
+}
+
+func (m *blockingAudioIndicator) PlaySound(event SoundEvent) <-chan struct{} {
+	m.mockAudioIndicator.PlaySound(event)
+	return m.release
+}
+
+// TestIndicatorManager_StartSound tests that only the start sound is waited for
+func TestIndicatorManager_StartSound(t *testing.T) {
+	manager := NewIndicatorManager()
+	audioMock := &blockingAudioIndicator{release: make(chan struct{})}
+	manager.SetAudioIndicator(audioMock)
+
+	// Other sounds play in the background
+	manager.OnStateChange(state.Change{From: state.StateRecording, To: state.StateTranscribing})
+	manager.OnCancel()
+
+	returned := make(chan struct{})
+	go func() {
+		manager.OnStateChange(state.Change{From: state.StateIdle, To: state.StateRecording})
+		close(returned)
+	}()
+
+	select {
+	case <-returned:
+		t.Fatal("Expected OnStateChange to wait for the start sound")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(audioMock.release)
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		t.Fatal("Expected OnStateChange to return when the start sound ends")
 	}
 }
 
@@ -53,5 +147,79 @@ func TestIndicatorManager_NoIndicators(t *testing.T) {
 	manager := NewIndicatorManager()
 
 	// Should not panic when no indicators are set
-	manager.OnStateChange(state.StateIdle, state.StateRecording)
+	manager.OnStateChange(state.Change{Seq: 1, From: state.StateIdle, To: state.StateRecording})
+}
+
+// TestIndicatorManager_OnLevelChange tests that input levels reach the visual indicator
+func TestIndicatorManager_OnLevelChange(t *testing.T) {
+	manager := NewIndicatorManager()
+
+	// Should not panic when no visual indicator is set
+	manager.OnLevelChange(0.5)
+
+	visualMock := &mockVisualIndicator{}
+	manager.SetVisualIndicator(visualMock)
+	manager.OnLevelChange(0.5)
+
+	if visualMock.levelCount != 1 {
+		t.Errorf("Expected visual indicator level update once, got %d", visualMock.levelCount)
+	}
+}
+
+// mockNotificationIndicator records notifications
+type mockNotificationIndicator struct {
+	notifications []Notification
+}
+
+func (m *mockNotificationIndicator) Notify(notification Notification) error {
+	m.notifications = append(m.notifications, notification)
+	return nil
+}
+
+func (m *mockNotificationIndicator) Close() error { return nil }
+
+// TestIndicatorManager_ErrorNotification tests that failures are reported by a notification,
+// except for queued recordings
+func TestIndicatorManager_ErrorNotification(t *testing.T) {
+	manager := NewIndicatorManager()
+	notifier := &mockNotificationIndicator{}
+	manager.SetNotificationIndicator(notifier)
+
+	failed := session.New(time.Now())
+	failed.SetResult("chat", "", errors.New("rate limited"))
+	manager.OnStateChange(state.Change{From: state.StateIdle, To: state.StateRecording})
+	manager.OnStateChange(state.Change{From: state.StateTranscribing, To: state.StateError, Event: state.EventFail, Session: failed})
+	manager.OnStateChange(state.Change{From: state.StateTranscribing, To: state.StateError, Event: state.EventTimeout, Session: session.New(time.Now())})
+
+	// A queued recording is not reported twice
+	queued := session.New(time.Now())
+	queued.SetResult("chat", "", errors.New("offline"))
+	queued.SetQueued()
+	manager.OnStateChange(state.Change{From: state.StateTranscribing, To: state.StateError, Event: state.EventFail, Session: queued})
+
+	if len(notifier.notifications) != 2 {
+		t.Fatalf("Expected two notifications, got %+v", notifier.notifications)
+	}
+	if n := notifier.notifications[0]; n.Body != "rate limited" || n.Urgency != UrgencyCritical {
+		t.Errorf("Unexpected failure notification: %+v", n)
+	}
+	if n := notifier.notifications[1]; !strings.Contains(n.Body, "too long") {
+		t.Errorf("Unexpected timeout notification: %+v", n)
+	}
+}
+
+// TestIndicatorManager_OnCancel tests that a cancellation plays the cancel sound
+func TestIndicatorManager_OnCancel(t *testing.T) {
+	manager := NewIndicatorManager()
+
+	// Should not panic when no audio indicator is set
+	manager.OnCancel()
+
+	audioMock := &mockAudioIndicator{}
+	manager.SetAudioIndicator(audioMock)
+	manager.OnCancel()
+
+	if len(audioMock.events) != 1 || audioMock.events[0] != SoundCancel {
+		t.Errorf("Expected one cancel sound, got %v", audioMock.events)
+	}
 }
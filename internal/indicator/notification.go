@@ -0,0 +1,268 @@
+package indicator
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/d-mozulyov/vox/internal/platform"
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	// maxPendingActions limits how many notifications keep their action handlers:
+	// a server may never report that a notification was closed
+	maxPendingActions = 20
+
+	notificationsName      = "org.freedesktop.Notifications"
+	notificationsPath      = dbus.ObjectPath("/org/freedesktop/Notifications")
+	notificationsInterface = "org.freedesktop.Notifications"
+
+	// notificationIcon is the freedesktop icon name shown with notifications
+	notificationIcon = "audio-input-microphone"
+)
+
+// Urgency is the urgency level of a notification
+type Urgency byte
+
+const (
+	UrgencyLow      Urgency = 0
+	UrgencyNormal   Urgency = 1
+	UrgencyCritical Urgency = 2
+)
+
+// Action is a button of a notification
+type Action struct {
+	Label string
+	Run   func() // called in its own goroutine each time the button is clicked
+}
+
+// Notification is a desktop notification
+type Notification struct {
+	Summary string
+	Body    string
+	Urgency Urgency
+	Actions []Action // dropped if the notification server does not support actions
+}
+
+// NotificationIndicator defines the interface for desktop notifications
+type NotificationIndicator interface {
+	// Notify shows a notification and returns at once
+	Notify(notification Notification) error
+
+	// Close disconnects from the notification server
+	Close() error
+}
+
+// notificationEvent is a signal of the notification server
+type notificationEvent struct {
+	id     uint32
+	action string // key of the clicked action, empty if the notification was closed
+}
+
+// notificationServer is the part of org.freedesktop.Notifications the indicator uses
+// Implemented over D-Bus by dbusNotifications, and by a fake in tests
+type notificationServer interface {
+	// notify shows a notification; actions alternate keys and labels
+	notify(summary, body string, actions []string, urgency Urgency) (uint32, error)
+
+	// supportsActions reports whether the server shows action buttons
+	supportsActions() bool
+
+	// events delivers clicked actions and closed notifications until close is called
+	events() <-chan notificationEvent
+
+	close() error
+}
+
+// notificationIndicator implements the NotificationIndicator interface
+type notificationIndicator struct {
+	server notificationServer
+
+	mutex   sync.Mutex
+	actions map[uint32][]Action // actions of shown notifications by ID
+	order   []uint32            // IDs in actions, oldest first
+}
+
+// NewNotificationIndicator connects to the notification server on the session bus
+// Fails where there is none, e.g. outside a freedesktop desktop (Windows, macOS)
+func NewNotificationIndicator() (NotificationIndicator, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the session bus: %w", err)
+	}
+	server, err := newDBusNotifications(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return newNotificationIndicator(server), nil
+}
+
+// newNotificationIndicator creates a notification indicator on top of a server
+func newNotificationIndicator(server notificationServer) *notificationIndicator {
+	ni := &notificationIndicator{
+		server:  server,
+		actions: make(map[uint32][]Action),
+	}
+	go ni.handleEvents()
+	return ni
+}
+
+// Notify shows a notification
+func (ni *notificationIndicator) Notify(notification Notification) error {
+	actions := notification.Actions
+	if !ni.server.supportsActions() {
+		actions = nil
+	}
+	var keys []string
+	for i, action := range actions {
+		keys = append(keys, strconv.Itoa(i), action.Label)
+	}
+
+	// Held while notifying, so an action clicked at once finds its handler
+	ni.mutex.Lock()
+	defer ni.mutex.Unlock()
+
+	id, err := ni.server.notify(notification.Summary, notification.Body, keys, notification.Urgency)
+	if err != nil {
+		return fmt.Errorf("failed to show notification: %w", err)
+	}
+
+	if len(actions) > 0 {
+		ni.actions[id] = actions
+		ni.order = append(ni.order, id)
+		for len(ni.order) > maxPendingActions {
+			delete(ni.actions, ni.order[0])
+			ni.order = ni.order[1:]
+		}
+	}
+	return nil
+}
+
+// Close disconnects from the notification server
+func (ni *notificationIndicator) Close() error {
+	return ni.server.close()
+}
+
+// handleEvents runs the actions clicked and forgets closed notifications
+func (ni *notificationIndicator) handleEvents() {
+	for event := range ni.server.events() {
+		ni.mutex.Lock()
+		actions := ni.actions[event.id]
+		if event.action == "" {
+			ni.forget(event.id)
+		}
+		ni.mutex.Unlock()
+
+		if event.action == "" {
+			continue
+		}
+		index, err := strconv.Atoi(event.action)
+		if err != nil || index < 0 || index >= len(actions) {
+			continue
+		}
+		platform.GetLogger().Info("Notification action clicked: %s", actions[index].Label)
+		// A slow action (e.g. a retry) must not hold up the events of other notifications
+		go actions[index].Run()
+	}
+}
+
+// forget drops the actions of a notification
+// Must be called with the mutex held
+func (ni *notificationIndicator) forget(id uint32) {
+	if _, ok := ni.actions[id]; !ok {
+		return
+	}
+	delete(ni.actions, id)
+	for i, pending := range ni.order {
+		if pending == id {
+			ni.order = append(ni.order[:i], ni.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// dbusNotifications talks to org.freedesktop.Notifications over D-Bus
+type dbusNotifications struct {
+	conn    *dbus.Conn
+	object  dbus.BusObject
+	actions bool
+	signals chan *dbus.Signal
+	out     chan notificationEvent
+}
+
+// newDBusNotifications checks that a notification server is running and subscribes to its signals
+func newDBusNotifications(conn *dbus.Conn) (*dbusNotifications, error) {
+	n := &dbusNotifications{
+		conn:    conn,
+		object:  conn.Object(notificationsName, notificationsPath),
+		signals: make(chan *dbus.Signal, 16),
+		out:     make(chan notificationEvent, 16),
+	}
+
+	var capabilities []string
+	if err := n.object.Call(notificationsInterface+".GetCapabilities", 0).Store(&capabilities); err != nil {
+		return nil, fmt.Errorf("no notification server: %w", err)
+	}
+	for _, capability := range capabilities {
+		if capability == "actions" {
+			n.actions = true
+		}
+	}
+
+	if err := conn.AddMatchSignal(
+		dbus.WithMatchObjectPath(notificationsPath),
+		dbus.WithMatchInterface(notificationsInterface),
+	); err != nil {
+		return nil, fmt.Errorf("failed to subscribe to notification signals: %w", err)
+	}
+	conn.Signal(n.signals)
+	go n.run()
+
+	return n, nil
+}
+
+func (n *dbusNotifications) notify(summary, body string, actions []string, urgency Urgency) (uint32, error) {
+	if actions == nil {
+		actions = []string{}
+	}
+	hints := map[string]dbus.Variant{"urgency": dbus.MakeVariant(byte(urgency))}
+
+	var id uint32
+	err := n.object.Call(notificationsInterface+".Notify", 0,
+		"Vox", uint32(0), notificationIcon, summary, body, actions, hints, int32(-1)).Store(&id)
+	return id, err
+}
+
+func (n *dbusNotifications) supportsActions() bool {
+	return n.actions
+}
+
+func (n *dbusNotifications) events() <-chan notificationEvent {
+	return n.out
+}
+
+func (n *dbusNotifications) close() error {
+	return n.conn.Close()
+}
+
+// run converts D-Bus signals to events; the signal channel is closed with the connection
+func (n *dbusNotifications) run() {
+	defer close(n.out)
+	for signal := range n.signals {
+		switch signal.Name {
+		case notificationsInterface + ".ActionInvoked":
+			var id uint32
+			var action string
+			if err := dbus.Store(signal.Body, &id, &action); err == nil && action != "" {
+				n.out <- notificationEvent{id: id, action: action}
+			}
+		case notificationsInterface + ".NotificationClosed":
+			var id, reason uint32
+			if err := dbus.Store(signal.Body, &id, &reason); err == nil {
+				n.out <- notificationEvent{id: id}
+			}
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package indicator
+
+import (
+	"bufio"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// fakeNotificationServer records notifications and delivers events on demand
+type fakeNotificationServer struct {
+	mutex   sync.Mutex
+	shown   []Notification // actions hold only labels
+	actions bool
+	out     chan notificationEvent
+}
+
+func newFakeNotificationServer(actions bool) *fakeNotificationServer {
+	return &fakeNotificationServer{actions: actions, out: make(chan notificationEvent)}
+}
+
+func (f *fakeNotificationServer) notify(summary, body string, actions []string, urgency Urgency) (uint32, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	notification := Notification{Summary: summary, Body: body, Urgency: urgency}
+	for i := 1; i < len(actions); i += 2 {
+		notification.Actions = append(notification.Actions, Action{Label: actions[i]})
+	}
+	f.shown = append(f.shown, notification)
+	return uint32(len(f.shown)), nil
+}
+
+func (f *fakeNotificationServer) supportsActions() bool            { return f.actions }
+func (f *fakeNotificationServer) events() <-chan notificationEvent { return f.out }
+
+func (f *fakeNotificationServer) close() error {
+	close(f.out)
+	return nil
+}
+
+func (f *fakeNotificationServer) notifications() []Notification {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append([]Notification(nil), f.shown...)
+}
+
+// TestNotificationActions tests that clicked actions run their handlers until the notification closes
+func TestNotificationActions(t *testing.T) {
+	server := newFakeNotificationServer(true)
+	ni := newNotificationIndicator(server)
+	defer ni.Close()
+
+	clicked := make(chan string, 4)
+	err := ni.Notify(Notification{
+		Summary: "Transcript",
+		Body:    "hello",
+		Actions: []Action{
+			{Label: "Copy", Run: func() { clicked <- "copy" }},
+			{Label: "Retry", Run: func() { clicked <- "retry" }},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	shown := server.notifications()
+	if len(shown) != 1 || len(shown[0].Actions) != 2 || shown[0].Actions[1].Label != "Retry" {
+		t.Fatalf("Unexpected notifications: %+v", shown)
+	}
+
+	server.out <- notificationEvent{id: 1, action: "1"}
+	select {
+	case action := <-clicked:
+		if action != "retry" {
+			t.Errorf("Expected retry, got %s", action)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the action to run")
+	}
+
+	// Once closed, the notification's actions are forgotten
+	server.out <- notificationEvent{id: 1}
+	server.out <- notificationEvent{id: 1, action: "0"}
+	server.out <- notificationEvent{id: 99, action: "0"}
+	select {
+	case action := <-clicked:
+		t.Errorf("Unexpected action %s", action)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestNotificationSlowAction tests that an action still running does not block the next events
+func TestNotificationSlowAction(t *testing.T) {
+	server := newFakeNotificationServer(true)
+	ni := newNotificationIndicator(server)
+	defer ni.Close()
+
+	release := make(chan struct{})
+	defer close(release)
+	clicked := make(chan string, 1)
+	err := ni.Notify(Notification{
+		Summary: "Transcript",
+		Actions: []Action{
+			{Label: "Retry", Run: func() { <-release }},
+			{Label: "Copy", Run: func() { clicked <- "copy" }},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	server.out <- notificationEvent{id: 1, action: "0"}
+	select {
+	case server.out <- notificationEvent{id: 1, action: "1"}:
+	case <-time.After(time.Second):
+		t.Fatal("Expected events to be handled while an action is running")
+	}
+	select {
+	case <-clicked:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the action to run while the previous one is still running")
+	}
+}
+
+// TestNotificationWithoutActions tests that actions are dropped if the server cannot show them
+// and that only the latest notifications keep their handlers
+func TestNotificationWithoutActions(t *testing.T) {
+	server := newFakeNotificationServer(false)
+	ni := newNotificationIndicator(server)
+	defer ni.Close()
+
+	if err := ni.Notify(Notification{Summary: "Transcript", Actions: []Action{{Label: "Copy", Run: func() {}}}}); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if shown := server.notifications(); len(shown[0].Actions) != 0 {
+		t.Errorf("Expected no actions, got %+v", shown[0].Actions)
+	}
+
+	server.actions = true
+	for i := 0; i < maxPendingActions+5; i++ {
+		if err := ni.Notify(Notification{Summary: "Transcript", Actions: []Action{{Label: "Copy", Run: func() {}}}}); err != nil {
+			t.Fatalf("Notify failed: %v", err)
+		}
+	}
+	ni.mutex.Lock()
+	pending := len(ni.actions)
+	ni.mutex.Unlock()
+	if pending != maxPendingActions {
+		t.Errorf("Expected %d notifications with actions, got %d", maxPendingActions, pending)
+	}
+}
+
+// notificationDaemon is a minimal org.freedesktop.Notifications server exported on a private bus
+type notificationDaemon struct {
+	mutex  sync.Mutex
+	shown  []string
+	hints  []map[string]dbus.Variant
+	nextID uint32
+}
+
+func (d *notificationDaemon) GetCapabilities() ([]string, *dbus.Error) {
+	return []string{"body", "actions"}, nil
+}
+
+func (d *notificationDaemon) Notify(appName string, replacesID uint32, icon, summary, body string,
+	actions []string, hints map[string]dbus.Variant, timeout int32) (uint32, *dbus.Error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.nextID++
+	d.shown = append(d.shown, summary+": "+body+" ["+strings.Join(actions, ",")+"]")
+	d.hints = append(d.hints, hints)
+	return d.nextID, nil
+}
+
+// TestNotificationsOnPrivateBus tests the D-Bus client against a server on a private session bus
+func TestNotificationsOnPrivateBus(t *testing.T) {
+	daemonPath, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon is not installed")
+	}
+
+	cmd := exec.Command(daemonPath, "--session", "--nofork", "--print-address")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Skipf("Failed to start dbus-daemon: %v", err)
+	}
+	defer cmd.Process.Kill()
+
+	address, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatalf("Failed to read the bus address: %v", err)
+	}
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", strings.TrimSpace(address))
+
+	// The server side
+	serverConn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		t.Fatalf("Failed to connect the server: %v", err)
+	}
+	defer serverConn.Close()
+	daemon := &notificationDaemon{}
+	if err := serverConn.Export(daemon, notificationsPath, notificationsInterface); err != nil {
+		t.Fatal(err)
+	}
+	if reply, err := serverConn.RequestName(notificationsName, dbus.NameFlagDoNotQueue); err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("Failed to own %s: %v", notificationsName, err)
+	}
+
+	ni, err := NewNotificationIndicator()
+	if err != nil {
+		t.Fatalf("NewNotificationIndicator failed: %v", err)
+	}
+	defer ni.Close()
+
+	copied := make(chan struct{})
+	err = ni.Notify(Notification{
+		Summary: "Transcript",
+		Body:    "hello",
+		Urgency: UrgencyLow,
+		Actions: []Action{{Label: "Copy", Run: func() { close(copied) }}},
+	})
+	if err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	daemon.mutex.Lock()
+	shown, hints := daemon.shown, daemon.hints
+	daemon.mutex.Unlock()
+	if len(shown) != 1 || shown[0] != "Transcript: hello [0,Copy]" {
+		t.Fatalf("Unexpected notifications: %q", shown)
+	}
+	if urgency, ok := hints[0]["urgency"].Value().(byte); !ok || urgency != byte(UrgencyLow) {
+		t.Errorf("Unexpected urgency hint: %v", hints[0]["urgency"])
+	}
+
+	// The user clicks Copy
+	if err := serverConn.Emit(notificationsPath, notificationsInterface+".ActionInvoked", uint32(1), "0"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-copied:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the Copy action to run")
+	}
+}
@@ -0,0 +1,172 @@
+package indicator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/d-mozulyov/vox/internal/platform"
+)
+
+// SoundEvent names a moment that plays a sound
+type SoundEvent string
+
+const (
+	SoundStart    SoundEvent = "start"    // recording started
+	SoundStop     SoundEvent = "stop"     // recording stopped, transcription started
+	SoundCancel   SoundEvent = "cancel"   // recording or transcription cancelled
+	SoundError    SoundEvent = "error"    // transcription failed or timed out
+	SoundInserted SoundEvent = "inserted" // transcript inserted
+)
+
+// DefaultTheme is the name of the built-in sound theme
+const DefaultTheme = "default"
+
+// ThemeManifest is the file of a theme directory that maps events to sound files, with
+// an optional volume of the theme and gain of single events (1 keeps the level), e.g.
+// {"volume": 0.7, "sounds": {"start": "start.wav", "stop": "stop.wav"}, "gains": {"stop": 1.5}}
+const ThemeManifest = "theme.json"
+
+// builtinSounds are the files of the built-in theme
+var builtinSounds = map[SoundEvent]string{
+	SoundStart:    "start_recording.wav",
+	SoundStop:     "stop_recording.wav",
+	SoundCancel:   "cancelled.wav",
+	SoundError:    "error.wav",
+	SoundInserted: "inserted.wav",
+}
+
+// Theme maps sound events to WAV files
+// Events missing from a theme play the sound of the built-in theme
+type Theme struct {
+	Name   string
+	Files  fs.FS                  // the theme directory
+	Sounds map[SoundEvent]string  // file names in Files
+	Volume float64                // gain of all sounds of the theme
+	Gains  map[SoundEvent]float64 // gain of single sounds, on top of Volume
+}
+
+// themeManifest is the content of ThemeManifest
+type themeManifest struct {
+	Volume *float64               `json:"volume"`
+	Sounds map[SoundEvent]string  `json:"sounds"`
+	Gains  map[SoundEvent]float64 `json:"gains"`
+}
+
+// BuiltinTheme returns the built-in theme with its sounds in files
+func BuiltinTheme(files fs.FS) Theme {
+	return Theme{Name: DefaultTheme, Files: files, Sounds: builtinSounds, Volume: 1}
+}
+
+// LoadTheme reads the theme in dir; the theme is named after the directory
+// Entries of unknown events or missing files are skipped, so the built-in sounds play instead
+func LoadTheme(dir string) (Theme, error) {
+	files := os.DirFS(dir)
+	data, err := fs.ReadFile(files, ThemeManifest)
+	if err != nil {
+		return Theme{}, fmt.Errorf("failed to read theme manifest: %w", err)
+	}
+	var manifest themeManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return Theme{}, fmt.Errorf("failed to parse theme manifest %s: %w", dir, err)
+	}
+
+	logger := platform.GetLogger()
+	theme := Theme{
+		Name:   filepath.Base(dir),
+		Files:  files,
+		Sounds: make(map[SoundEvent]string),
+		Volume: 1,
+		Gains:  make(map[SoundEvent]float64),
+	}
+	if manifest.Volume != nil {
+		if *manifest.Volume < 0 {
+			logger.Warn("Theme %s: negative volume %v is ignored", theme.Name, *manifest.Volume)
+		} else {
+			theme.Volume = *manifest.Volume
+		}
+	}
+	for event, gain := range manifest.Gains {
+		if _, ok := builtinSounds[event]; !ok || gain < 0 {
+			logger.Warn("Theme %s: gain %v of sound event %q is ignored", theme.Name, gain, event)
+			continue
+		}
+		theme.Gains[event] = gain
+	}
+	for event, file := range manifest.Sounds {
+		if _, ok := builtinSounds[event]; !ok {
+			logger.Warn("Theme %s: unknown sound event %q", theme.Name, event)
+			continue
+		}
+		if _, err := fs.Stat(files, file); err != nil {
+			logger.Warn("Theme %s: sound of %s is unavailable, the built-in one is used: %v", theme.Name, event, err)
+			continue
+		}
+		theme.Sounds[event] = file
+	}
+	return theme, nil
+}
+
+// LoadThemes loads the themes in the subdirectories of dir, sorted by name
+// Subdirectories without a manifest are skipped, and so is one named like the built-in theme
+func LoadThemes(dir string) ([]Theme, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list themes: %w", err)
+	}
+
+	logger := platform.GetLogger()
+	var themes []Theme
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if entry.Name() == DefaultTheme {
+			logger.Warn("Theme %s is skipped: the name is taken by the built-in theme", filepath.Join(dir, entry.Name()))
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if _, err := os.Stat(filepath.Join(path, ThemeManifest)); err != nil {
+			continue
+		}
+		theme, err := LoadTheme(path)
+		if err != nil {
+			logger.Warn("Theme %s is skipped: %v", path, err)
+			continue
+		}
+		themes = append(themes, theme)
+	}
+
+	sort.Slice(themes, func(a, b int) bool {
+		return themes[a].Name < themes[b].Name
+	})
+	return themes, nil
+}
+
+// sound returns the sound of an event in the theme, or in fallback if the theme has none:
+// a key naming the theme and file, and the gain set by the theme it comes from
+// Returns an empty key if neither has a sound for the event
+func (t Theme) sound(event SoundEvent, fallback Theme) (key string, gain float64) {
+	for _, theme := range []Theme{t, fallback} {
+		if file, ok := theme.Sounds[event]; ok {
+			gain = theme.Volume
+			if eventGain, ok := theme.Gains[event]; ok {
+				gain *= eventGain
+			}
+			return soundKey(theme, file), gain
+		}
+	}
+	return "", 0
+}
+
+// soundKey identifies a sound file of a theme
+func soundKey(theme Theme, file string) string {
+	return theme.Name + "/" + file
+}
@@ -0,0 +1,67 @@
+package indicator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTheme creates a theme directory with a manifest and the given sound files
+func writeTheme(t *testing.T, dir, manifest string, files ...string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ThemeManifest), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte("RIFF"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestLoadThemes tests theme discovery and the fallback to built-in sounds
+func TestLoadThemes(t *testing.T) {
+	dir := t.TempDir()
+	writeTheme(t, filepath.Join(dir, "soft"), `{"volume": 0.5, "sounds": {"start": "a.wav", "stop": "missing.wav", "beep": "a.wav"}, "gains": {"start": 1.5, "stop": -1}}`, "a.wav")
+	writeTheme(t, filepath.Join(dir, "bell"), `{"sounds": {}}`)
+	writeTheme(t, filepath.Join(dir, DefaultTheme), `{"sounds": {}}`)
+	writeTheme(t, filepath.Join(dir, "broken"), `{`)
+	if err := os.MkdirAll(filepath.Join(dir, "empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	themes, err := LoadThemes(dir)
+	if err != nil {
+		t.Fatalf("LoadThemes failed: %v", err)
+	}
+	if len(themes) != 2 || themes[0].Name != "bell" || themes[1].Name != "soft" {
+		t.Fatalf("Expected themes bell and soft, got %+v", themes)
+	}
+
+	soft := themes[1]
+	if len(soft.Sounds) != 1 || len(soft.Gains) != 1 {
+		t.Errorf("Expected only the start sound and gain to be kept, got %v and %v", soft.Sounds, soft.Gains)
+	}
+	if themes[0].Volume != 1 {
+		t.Errorf("Expected full volume by default, got %v", themes[0].Volume)
+	}
+
+	builtin := BuiltinTheme(nil)
+	if key, gain := soft.sound(SoundStart, builtin); key != "soft/a.wav" || gain != 0.75 {
+		t.Errorf("Unexpected start sound: %s at %v", key, gain)
+	}
+	// The built-in sound keeps its own volume
+	if path, gain := soft.sound(SoundStop, builtin); path != "default/stop_recording.wav" || gain != 1 {
+		t.Errorf("Expected the built-in stop sound, got %s at %v", path, gain)
+	}
+	if path, _ := soft.sound(SoundEvent("beep"), builtin); path != "" {
+		t.Errorf("Expected no sound for an unknown event, got %s", path)
+	}
+
+	if themes, err := LoadThemes(filepath.Join(dir, "none")); err != nil || themes != nil {
+		t.Errorf("Expected no themes in a missing directory, got %v, %v", themes, err)
+	}
+}
@@ -2,24 +2,43 @@ package indicator
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
+	"io/fs"
 	"runtime"
+	"sync"
+	"time"
 
 	"github.com/d-mozulyov/vox/internal/platform"
 	"github.com/d-mozulyov/vox/internal/state"
 )
 
+const (
+	// levelIconCount is the number of input level icons (recording_lvl0..3)
+	levelIconCount = 4
+	// levelUpdateInterval limits how often the level icon may change
+	levelUpdateInterval = 150 * time.Millisecond
+)
+
 // VisualIndicator defines the interface for visual state indication
 type VisualIndicator interface {
 	// UpdateIcon updates the tray icon based on the current state
 	UpdateIcon(state state.State) error
+
+	// UpdateLevel shows the microphone input level (0.0 to 1.0) while recording
+	// Updates are throttled, so it is safe to call for every captured frame
+	UpdateLevel(level float64) error
 }
 
 // visualIndicator implements the VisualIndicator interface
 type visualIndicator struct {
 	iconSetter IconSetter
 	icons      map[state.State][]byte
+	levelIcons [][]byte
+
+	// Current state and the last shown level icon (-1 if none)
+	current     state.State
+	levelIcon   int
+	levelUpdate time.Time
+	mutex       sync.Mutex
 }
 
 // IconSetter defines the interface for setting tray icons
@@ -30,8 +49,8 @@ type IconSetter interface {
 
 // NewVisualIndicator creates a new visual indicator instance
 // iconSetter is the component responsible for actually updating the tray icon
-// iconsPath is the directory containing icon files
-func NewVisualIndicator(iconSetter IconSetter, iconsPath string) (VisualIndicator, error) {
+// icons holds the icon files
+func NewVisualIndicator(iconSetter IconSetter, icons fs.FS) (VisualIndicator, error) {
 	logger := platform.GetLogger()
 
 	if iconSetter == nil {
@@ -42,10 +61,11 @@ func NewVisualIndicator(iconSetter IconSetter, iconsPath string) (VisualIndicato
 	vi := &visualIndicator{
 		iconSetter: iconSetter,
 		icons:      make(map[state.State][]byte),
+		levelIcon:  -1,
 	}
 
 	// Load icons for each state
-	if err := vi.loadIcons(iconsPath); err != nil {
+	if err := vi.loadIcons(icons); err != nil {
 		logger.Error("Visual indicator initialization failed: %v", err)
 		return nil, fmt.Errorf("failed to load icons: %w", err)
 	}
@@ -55,47 +75,40 @@ func NewVisualIndicator(iconSetter IconSetter, iconsPath string) (VisualIndicato
 	return vi, nil
 }
 
-// loadIcons loads icon files from the specified directory
+// loadIcons loads the icon files
 // On Windows, ICO format is required. On other platforms, PNG is used.
-func (vi *visualIndicator) loadIcons(iconsPath string) error {
+func (vi *visualIndicator) loadIcons(icons fs.FS) error {
 	logger := platform.GetLogger()
 
-	// Determine icon extension and filename based on platform
-	var iconFiles map[state.State]string
+	// Determine icon filename suffix based on platform
+	var suffix string
 
 	if runtime.GOOS == "windows" {
 		// Windows: use ICO files (contain multiple sizes)
-		iconFiles = map[state.State]string{
-			state.StateIdle:      "idle.ico",
-			state.StateRecording: "recording.ico",
-		}
+		suffix = ".ico"
 	} else if runtime.GOOS == "darwin" {
 		// macOS: detect Retina and use appropriate size
-		var size string
 		if isRetina() {
-			size = "44" // Retina: use 44px for @2x
+			suffix = "_44.png" // Retina: use 44px for @2x
 			logger.Info("Retina display detected, using 44px icons")
 		} else {
-			size = "22" // Non-Retina: use 22px
+			suffix = "_22.png" // Non-Retina: use 22px
 			logger.Info("Non-Retina display detected, using 22px icons")
 		}
-		iconFiles = map[state.State]string{
-			state.StateIdle:      "idle_" + size + ".png",
-			state.StateRecording: "recording_" + size + ".png",
-		}
 	} else {
 		// Linux: use 24px
-		iconFiles = map[state.State]string{
-			state.StateIdle:      "idle_24.png",
-			state.StateRecording: "recording_24.png",
-		}
+		suffix = "_24.png"
 	}
 
-	logger.Info("Loading icons from: %s", iconsPath)
+	iconFiles := map[state.State]string{
+		state.StateIdle:         "idle" + suffix,
+		state.StateRecording:    "recording" + suffix,
+		state.StateTranscribing: "transcribing" + suffix,
+		state.StateError:        "error" + suffix,
+	}
 
 	for state, filename := range iconFiles {
-		iconPath := filepath.Join(iconsPath, filename)
-		data, err := os.ReadFile(iconPath)
+		data, err := fs.ReadFile(icons, filename)
 		if err != nil {
 			logger.Error("Failed to read icon %s: %v", filename, err)
 			return fmt.Errorf("failed to read icon %s: %w", filename, err)
@@ -104,11 +117,28 @@ func (vi *visualIndicator) loadIcons(iconsPath string) error {
 		logger.Info("Icon loaded: %s (%d bytes)", filename, len(data))
 	}
 
+	// Level icons are optional: without them the plain recording icon stays
+	levelIcons := make([][]byte, 0, levelIconCount)
+	for i := 0; i < levelIconCount; i++ {
+		filename := fmt.Sprintf("recording_lvl%d%s", i, suffix)
+		data, err := fs.ReadFile(icons, filename)
+		if err != nil {
+			logger.Warn("Input level icons not available: %v", err)
+			return nil
+		}
+		levelIcons = append(levelIcons, data)
+	}
+	vi.levelIcons = levelIcons
+	logger.Info("Input level icons loaded: %d", len(levelIcons))
+
 	return nil
 }
 
 // UpdateIcon updates the tray icon to reflect the current state
 func (vi *visualIndicator) UpdateIcon(s state.State) error {
+	vi.mutex.Lock()
+	defer vi.mutex.Unlock()
+
 	logger := platform.GetLogger()
 
 	iconData, ok := vi.icons[s]
@@ -122,7 +152,41 @@ func (vi *visualIndicator) UpdateIcon(s state.State) error {
 		return fmt.Errorf("failed to set icon for state %s: %w", s.String(), err)
 	}
 
+	vi.current = s
+	vi.levelIcon = -1
+
 	logger.Info("Icon updated to state: %s", s.String())
 
 	return nil
 }
+
+// UpdateLevel switches between input level icons while recording
+// The icon changes at most once per levelUpdateInterval and only if the level bucket changed
+func (vi *visualIndicator) UpdateLevel(level float64) error {
+	vi.mutex.Lock()
+	defer vi.mutex.Unlock()
+
+	if vi.current != state.StateRecording || len(vi.levelIcons) == 0 {
+		return nil
+	}
+
+	index := int(level * float64(len(vi.levelIcons)))
+	if index < 0 {
+		index = 0
+	} else if index >= len(vi.levelIcons) {
+		index = len(vi.levelIcons) - 1
+	}
+
+	if index == vi.levelIcon || time.Since(vi.levelUpdate) < levelUpdateInterval {
+		return nil
+	}
+
+	if err := vi.iconSetter.SetIcon(vi.levelIcons[index]); err != nil {
+		return fmt.Errorf("failed to set level icon %d: %w", index, err)
+	}
+
+	vi.levelIcon = index
+	vi.levelUpdate = time.Now()
+
+	return nil
+}
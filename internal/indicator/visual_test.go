@@ -0,0 +1,58 @@
+package indicator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/d-mozulyov/vox/internal/state"
+)
+
+// mockIconSetter records icons passed to SetIcon
+type mockIconSetter struct {
+	icons [][]byte
+}
+
+func (m *mockIconSetter) SetIcon(iconData []byte) error {
+	m.icons = append(m.icons, iconData)
+	return nil
+}
+
+// TestVisualIndicator_UpdateLevel tests level icon selection and throttling
+func TestVisualIndicator_UpdateLevel(t *testing.T) {
+	setter := &mockIconSetter{}
+	vi := &visualIndicator{
+		iconSetter: setter,
+		icons: map[state.State][]byte{
+			state.StateIdle:      []byte("idle"),
+			state.StateRecording: []byte("recording"),
+		},
+		levelIcons: [][]byte{[]byte("lvl0"), []byte("lvl1"), []byte("lvl2"), []byte("lvl3")},
+		levelIcon:  -1,
+	}
+
+	// Levels are ignored while not recording
+	vi.UpdateLevel(1)
+	if len(setter.icons) != 0 {
+		t.Fatalf("Expected no icon updates in Idle, got %d", len(setter.icons))
+	}
+
+	vi.UpdateIcon(state.StateRecording)
+	vi.UpdateLevel(0.9)
+	if last := string(setter.icons[len(setter.icons)-1]); last != "lvl3" {
+		t.Errorf("Expected lvl3 for level 0.9, got %s", last)
+	}
+
+	// A different level right away is throttled
+	count := len(setter.icons)
+	vi.UpdateLevel(0.1)
+	if len(setter.icons) != count {
+		t.Errorf("Expected level update to be throttled")
+	}
+
+	// After the interval the new level is shown
+	vi.levelUpdate = time.Now().Add(-levelUpdateInterval)
+	vi.UpdateLevel(0.1)
+	if last := string(setter.icons[len(setter.icons)-1]); last != "lvl0" {
+		t.Errorf("Expected lvl0 for level 0.1, got %s", last)
+	}
+}
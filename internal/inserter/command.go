@@ -0,0 +1,73 @@
+//go:build !windows
+// +build !windows
+
+package inserter
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// commandInserter types text by running an external tool with the text as the last argument
+type commandInserter struct {
+	path string
+	args []string
+}
+
+// newCommandInserter finds the tool in PATH and creates an inserter for it
+func newCommandInserter(tool string, args ...string) (Inserter, error) {
+	path, err := exec.LookPath(tool)
+	if err != nil {
+		return nil, fmt.Errorf("%s is required to insert text: %w", tool, err)
+	}
+	return &commandInserter{path: path, args: args}, nil
+}
+
+// Insert types the text into the focused application
+func (ci *commandInserter) Insert(text string) error {
+	args := append(append([]string{}, ci.args...), text)
+	output, err := exec.Command(ci.path, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to insert text: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// runTool runs an external tool found in PATH with input on its stdin
+// Returns the trimmed stdout. Not for tools that leave a process running in the
+// background (clipboard tools): it would inherit the pipes and block until it exits
+func runTool(input string, tool string, args ...string) (string, error) {
+	path, err := exec.LookPath(tool)
+	if err != nil {
+		return "", fmt.Errorf("%s not found: %w", tool, err)
+	}
+
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = strings.NewReader(input)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%s failed: %w: %s", tool, err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// feedTool runs an external tool found in PATH with input on its stdin, discarding its output
+// Clipboard tools (xclip, wl-copy) fork a process that keeps serving the clipboard until
+// another application takes it over; as no output is captured, it holds no pipe of ours
+// and feedTool returns as soon as the tool itself exits
+func feedTool(input string, tool string, args ...string) error {
+	path, err := exec.LookPath(tool)
+	if err != nil {
+		return fmt.Errorf("%s not found: %w", tool, err)
+	}
+
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = strings.NewReader(input)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %w", tool, err)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+//go:build !windows
+// +build !windows
+
+package inserter
+
+import (
+	"testing"
+	"time"
+)
+
+// TestFeedToolBackground tests that a tool leaving a background process does not block,
+// as xclip does to keep serving the clipboard
+func TestFeedToolBackground(t *testing.T) {
+	done := make(chan error, 1)
+	go func() {
+		done <- feedTool("hello", "sh", "-c", "cat > /dev/null; sleep 30 &")
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("feedTool failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected feedTool to return while the background process runs")
+	}
+}
@@ -0,0 +1,73 @@
+// Package inserter types transcribed text into the focused application.
+package inserter
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Inserter defines the interface for inserting text at the cursor position
+type Inserter interface {
+	// Insert types the text into the focused application
+	Insert(text string) error
+}
+
+// WordBuffer passes streamed text to an inserter in whole words
+// Text is held back until a word boundary (whitespace) arrives, so a word is
+// never typed in pieces. Flush inserts the rest when the stream is complete.
+type WordBuffer struct {
+	inserter Inserter
+	pending  string
+	inserted strings.Builder
+}
+
+// NewWordBuffer creates a word buffer that inserts text with the given inserter
+func NewWordBuffer(inserter Inserter) *WordBuffer {
+	return &WordBuffer{inserter: inserter}
+}
+
+// Write adds streamed text and inserts everything up to the last word boundary
+func (b *WordBuffer) Write(text string) error {
+	b.pending += text
+
+	boundary := strings.LastIndexFunc(b.pending, unicode.IsSpace)
+	if boundary < 0 {
+		return nil
+	}
+	_, size := utf8.DecodeRuneInString(b.pending[boundary:])
+	boundary += size
+
+	if err := b.insert(b.pending[:boundary]); err != nil {
+		return err
+	}
+	b.pending = b.pending[boundary:]
+
+	return nil
+}
+
+// Flush inserts the text held back since the last word boundary
+func (b *WordBuffer) Flush() error {
+	if b.pending == "" {
+		return nil
+	}
+	if err := b.insert(b.pending); err != nil {
+		return err
+	}
+	b.pending = ""
+	return nil
+}
+
+// Inserted returns the text that has been inserted so far
+func (b *WordBuffer) Inserted() string {
+	return b.inserted.String()
+}
+
+// insert passes text to the inserter and remembers it on success
+func (b *WordBuffer) insert(text string) error {
+	if err := b.inserter.Insert(text); err != nil {
+		return err
+	}
+	b.inserted.WriteString(text)
+	return nil
+}
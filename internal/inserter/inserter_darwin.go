@@ -0,0 +1,29 @@
+//go:build darwin
+// +build darwin
+
+package inserter
+
+// typeScript types its first argument via System Events
+// Passing the text as an argument avoids escaping it inside the script
+const typeScript = `on run argv
+tell application "System Events" to keystroke (item 1 of argv)
+end run`
+
+// frontmostScript prints the bundle identifier of the frontmost application
+const frontmostScript = `tell application "System Events" to get bundle identifier of first application process whose frontmost is true`
+
+// NewInserter creates an inserter that types text with osascript
+// Vox needs the Accessibility permission for this to work
+func NewInserter() (Inserter, error) {
+	return newCommandInserter("osascript", "-e", typeScript, "--")
+}
+
+// FocusedWindow returns the bundle identifier of the frontmost application
+func FocusedWindow() (string, error) {
+	return runTool("", "osascript", "-e", frontmostScript)
+}
+
+// CopyToClipboard puts the text on the clipboard with pbcopy
+func CopyToClipboard(text string) error {
+	return feedTool(text, "pbcopy")
+}
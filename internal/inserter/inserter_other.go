@@ -0,0 +1,49 @@
+//go:build !windows && !darwin
+// +build !windows,!darwin
+
+package inserter
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+
+	"github.com/d-mozulyov/vox/internal/platform"
+)
+
+// NewInserter creates an inserter that types text with wtype on Wayland
+// and with xdotool on X11
+func NewInserter() (Inserter, error) {
+	logger := platform.GetLogger()
+
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		logger.Info("Using wtype to insert text (Wayland)")
+		return newCommandInserter("wtype", "--")
+	}
+
+	logger.Info("Using xdotool to insert text (X11)")
+	return newCommandInserter("xdotool", "type", "--clearmodifiers", "--delay", "0", "--")
+}
+
+// FocusedWindow returns the ID of the active X11 window
+// Wayland does not let clients see other windows, so there it returns an error
+func FocusedWindow() (string, error) {
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		return "", errors.New("the focused window is not available on Wayland")
+	}
+	return runTool("", "xdotool", "getactivewindow")
+}
+
+// CopyToClipboard puts the text on the clipboard with wl-copy on Wayland
+// and with xclip or xsel on X11
+func CopyToClipboard(text string) error {
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		return feedTool(text, "wl-copy")
+	}
+
+	err := feedTool(text, "xclip", "-selection", "clipboard")
+	if errors.Is(err, exec.ErrNotFound) {
+		err = feedTool(text, "xsel", "--clipboard", "--input")
+	}
+	return err
+}
@@ -0,0 +1,62 @@
+package inserter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// mockInserter records inserted text
+type mockInserter struct {
+	calls []string
+	err   error
+}
+
+func (m *mockInserter) Insert(text string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.calls = append(m.calls, text)
+	return nil
+}
+
+// TestWordBuffer tests that streamed text is inserted at word boundaries
+func TestWordBuffer(t *testing.T) {
+	mock := &mockInserter{}
+	buffer := NewWordBuffer(mock)
+
+	for _, delta := range []string{"Hel", "lo wor", "ld, ", "привет", " мир."} {
+		if err := buffer.Write(delta); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := buffer.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	expected := []string{"Hello ", "world, ", "привет ", "мир."}
+	if strings.Join(mock.calls, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected inserts %q, got %q", expected, mock.calls)
+	}
+	if buffer.Inserted() != "Hello world, привет мир." {
+		t.Errorf("Unexpected inserted text: %q", buffer.Inserted())
+	}
+}
+
+// TestWordBufferError tests that failed inserts are not reported as inserted
+func TestWordBufferError(t *testing.T) {
+	mock := &mockInserter{}
+	buffer := NewWordBuffer(mock)
+
+	if err := buffer.Write("Hello wor"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	mock.err = errors.New("no focused window")
+	if err := buffer.Write("ld "); err == nil {
+		t.Error("Expected insert error")
+	}
+	if buffer.Inserted() != "Hello " {
+		t.Errorf("Expected %q inserted, got %q", "Hello ", buffer.Inserted())
+	}
+}
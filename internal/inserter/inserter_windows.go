@@ -0,0 +1,146 @@
+//go:build windows
+// +build windows
+
+package inserter
+
+import (
+	"fmt"
+	"syscall"
+	"unicode/utf16"
+	"unsafe"
+)
+
+// Win32 SendInput constants
+const (
+	inputKeyboard    = 1
+	keyEventKeyUp    = 0x0002
+	keyEventUnicode  = 0x0004
+	virtualKeyReturn = 0x0D
+)
+
+// Win32 clipboard constants
+const (
+	clipboardUnicodeText = 13     // CF_UNICODETEXT
+	globalMoveable       = 0x0002 // GMEM_MOVEABLE
+)
+
+var (
+	user32   = syscall.NewLazyDLL("user32.dll")
+	kernel32 = syscall.NewLazyDLL("kernel32.dll")
+
+	procSendInput           = user32.NewProc("SendInput")
+	procGetForegroundWindow = user32.NewProc("GetForegroundWindow")
+	procOpenClipboard       = user32.NewProc("OpenClipboard")
+	procCloseClipboard      = user32.NewProc("CloseClipboard")
+	procEmptyClipboard      = user32.NewProc("EmptyClipboard")
+	procSetClipboardData    = user32.NewProc("SetClipboardData")
+	procGlobalAlloc         = kernel32.NewProc("GlobalAlloc")
+	procGlobalFree          = kernel32.NewProc("GlobalFree")
+	procGlobalLock          = kernel32.NewProc("GlobalLock")
+	procGlobalUnlock        = kernel32.NewProc("GlobalUnlock")
+	procMoveMemory          = kernel32.NewProc("RtlMoveMemory")
+)
+
+// keyboardInput mirrors the Win32 INPUT structure with a KEYBDINPUT member
+// padding fills the union up to the size of MOUSEINPUT, its largest member
+type keyboardInput struct {
+	inputType uint32
+	keyboard  keybdInput
+	padding   [8]byte
+}
+
+// keybdInput mirrors the Win32 KEYBDINPUT structure
+type keybdInput struct {
+	virtualKey uint16
+	scanCode   uint16
+	flags      uint32
+	time       uint32
+	extraInfo  uintptr
+}
+
+// sendInputInserter types text with SendInput Unicode key events
+type sendInputInserter struct{}
+
+// NewInserter creates an inserter that types text with SendInput
+func NewInserter() (Inserter, error) {
+	if err := procSendInput.Find(); err != nil {
+		return nil, fmt.Errorf("SendInput is not available: %w", err)
+	}
+	return &sendInputInserter{}, nil
+}
+
+// Insert types the text into the focused application
+func (si *sendInputInserter) Insert(text string) error {
+	inputs := make([]keyboardInput, 0, len(text)*2)
+	for _, unit := range utf16.Encode([]rune(text)) {
+		down := keyboardInput{inputType: inputKeyboard}
+		switch unit {
+		case '\r':
+			continue
+		case '\n':
+			// Line breaks are typed as the Enter key
+			down.keyboard.virtualKey = virtualKeyReturn
+		default:
+			down.keyboard.scanCode = unit
+			down.keyboard.flags = keyEventUnicode
+		}
+		up := down
+		up.keyboard.flags |= keyEventKeyUp
+		inputs = append(inputs, down, up)
+	}
+	if len(inputs) == 0 {
+		return nil
+	}
+
+	sent, _, err := procSendInput.Call(uintptr(len(inputs)), uintptr(unsafe.Pointer(&inputs[0])), unsafe.Sizeof(inputs[0]))
+	if int(sent) != len(inputs) {
+		return fmt.Errorf("failed to insert text: %d of %d key events sent: %v", sent, len(inputs), err)
+	}
+	return nil
+}
+
+// FocusedWindow returns the handle of the foreground window
+func FocusedWindow() (string, error) {
+	hwnd, _, _ := procGetForegroundWindow.Call()
+	if hwnd == 0 {
+		return "", fmt.Errorf("no foreground window")
+	}
+	return fmt.Sprintf("%#x", hwnd), nil
+}
+
+// CopyToClipboard puts the text on the clipboard as CF_UNICODETEXT
+func CopyToClipboard(text string) error {
+	data, err := syscall.UTF16FromString(text)
+	if err != nil {
+		return fmt.Errorf("failed to encode text: %w", err)
+	}
+	size := uintptr(len(data)) * unsafe.Sizeof(data[0])
+
+	if ok, _, err := procOpenClipboard.Call(0); ok == 0 {
+		return fmt.Errorf("failed to open clipboard: %v", err)
+	}
+	defer procCloseClipboard.Call()
+
+	if ok, _, err := procEmptyClipboard.Call(); ok == 0 {
+		return fmt.Errorf("failed to empty clipboard: %v", err)
+	}
+
+	// The clipboard takes ownership of the memory once SetClipboardData succeeds
+	handle, _, err := procGlobalAlloc.Call(globalMoveable, size)
+	if handle == 0 {
+		return fmt.Errorf("failed to allocate clipboard memory: %v", err)
+	}
+	memory, _, err := procGlobalLock.Call(handle)
+	if memory == 0 {
+		procGlobalFree.Call(handle)
+		return fmt.Errorf("failed to lock clipboard memory: %v", err)
+	}
+	procMoveMemory.Call(memory, uintptr(unsafe.Pointer(&data[0])), size)
+	procGlobalUnlock.Call(handle)
+
+	if ok, _, err := procSetClipboardData.Call(clipboardUnicodeText, handle); ok == 0 {
+		procGlobalFree.Call(handle)
+		return fmt.Errorf("failed to set clipboard data: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+// Package journal keeps the audio of the active session on disk while it is
+// recorded and transcribed, so that it survives a crash of Vox.
+//
+// Each session is stored as <id>.pcm (raw 16-bit little-endian mono samples,
+// appended while recording) next to <id>.json in the journal directory.
+// Both are removed when the session ends normally, so any entry found at
+// startup belongs to a session that was interrupted.
+package journal
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/d-mozulyov/vox/internal/platform"
+)
+
+// flushInterval is how often buffered audio is written to disk while recording
+const flushInterval = 500 * time.Millisecond
+
+// Stage is the step of the pipeline a session had reached
+type Stage string
+
+const (
+	// StageRecording means the session was interrupted while recording
+	StageRecording Stage = "recording"
+	// StageTranscribing means the recording was finished but not yet transcribed
+	StageTranscribing Stage = "transcribing"
+)
+
+// Entry describes a journaled session and is stored in its JSON file
+type Entry struct {
+	ID         string            `json:"id"`
+	StartedAt  time.Time         `json:"started_at"`
+	SampleRate int               `json:"sample_rate"`
+	Stage      Stage             `json:"stage"`
+	Context    map[string]string `json:"context,omitempty"`
+}
+
+// Journal stores sessions in a directory
+type Journal struct {
+	dir string
+}
+
+// New creates a journal in dir
+func New(dir string) *Journal {
+	return &Journal{dir: dir}
+}
+
+// Begin starts journaling a session that is being recorded
+func (j *Journal) Begin(entry Entry) (*Session, error) {
+	if err := os.MkdirAll(j.dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	entry.Stage = StageRecording
+	if err := j.writeEntry(entry); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(j.audioPath(entry.ID), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		j.remove(entry.ID)
+		return nil, fmt.Errorf("failed to create journal audio: %w", err)
+	}
+
+	s := &Session{
+		journal: j,
+		entry:   entry,
+		file:    file,
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+// Unfinished returns the sessions left over by an interrupted run, oldest first
+func (j *Journal) Unfinished() ([]Entry, error) {
+	files, err := filepath.Glob(filepath.Join(j.dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list journal: %w", err)
+	}
+
+	var entries []Entry
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read journal entry: %w", err)
+		}
+		var entry Entry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			platform.GetLogger().Warn("Skipping invalid journal entry %s: %v", path, err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(a, b int) bool {
+		return entries[a].StartedAt.Before(entries[b].StartedAt)
+	})
+	return entries, nil
+}
+
+// Load returns the audio of a journaled session
+// A sample cut in half by the crash is dropped
+func (j *Journal) Load(id string) ([]int16, error) {
+	data, err := os.ReadFile(j.audioPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal audio: %w", err)
+	}
+
+	samples := make([]int16, len(data)/2)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(data[i*2:]))
+	}
+	return samples, nil
+}
+
+// Discard removes a journaled session
+func (j *Journal) Discard(id string) error {
+	if err := j.remove(id); err != nil {
+		return fmt.Errorf("failed to discard journal entry %s: %w", id, err)
+	}
+	return nil
+}
+
+// remove deletes the files of a session, ignoring the missing ones
+func (j *Journal) remove(id string) error {
+	var errs []error
+	for _, path := range []string{j.audioPath(id), j.entryPath(id)} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// writeEntry writes the metadata of a session atomically
+func (j *Journal) writeEntry(entry Entry) error {
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode journal entry: %w", err)
+	}
+
+	// A crash while writing must not leave a truncated entry behind
+	tmpPath := j.entryPath(entry.ID) + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write journal entry: %w", err)
+	}
+	if err := os.Rename(tmpPath, j.entryPath(entry.ID)); err != nil {
+		return fmt.Errorf("failed to write journal entry: %w", err)
+	}
+	return nil
+}
+
+func (j *Journal) audioPath(id string) string {
+	return filepath.Join(j.dir, id+".pcm")
+}
+
+func (j *Journal) entryPath(id string) string {
+	return filepath.Join(j.dir, id+".json")
+}
+
+// Session journals the audio of one session
+// Write may be called from the audio thread: it only buffers the samples,
+// which a background goroutine appends to the file every flushInterval.
+// Transcribing and Finish of a nil Session do nothing, so callers need not
+// check whether the session is journaled.
+type Session struct {
+	journal *Journal
+	entry   Entry
+	file    *os.File
+
+	mutex   sync.Mutex
+	pending []int16
+	closed  bool
+
+	once    sync.Once
+	done    chan struct{} // closed to stop the flush goroutine
+	stopped chan struct{} // closed when the flush goroutine has written everything
+}
+
+// ID returns the ID of the journaled session
+func (s *Session) ID() string {
+	return s.entry.ID
+}
+
+// Write buffers captured samples for the journal
+func (s *Session) Write(samples []int16) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.closed {
+		s.pending = append(s.pending, samples...)
+	}
+}
+
+// Transcribing writes the remaining audio, closes the audio file and marks
+// the session as waiting for transcription with the context captured so far
+func (s *Session) Transcribing(context map[string]string) error {
+	if s == nil {
+		return nil
+	}
+	if err := s.close(); err != nil {
+		return err
+	}
+	s.entry.Stage = StageTranscribing
+	s.entry.Context = context
+	return s.journal.writeEntry(s.entry)
+}
+
+// Finish ends journaling and removes the session: it was transcribed, discarded or queued
+func (s *Session) Finish() error {
+	if s == nil {
+		return nil
+	}
+	closeErr := s.close()
+	if err := s.journal.Discard(s.entry.ID); err != nil {
+		return err
+	}
+	return closeErr
+}
+
+// close stops the flush goroutine after it has written the pending samples
+func (s *Session) close() error {
+	var err error
+	s.once.Do(func() {
+		close(s.done)
+		<-s.stopped
+		err = s.file.Close()
+		if err != nil {
+			err = fmt.Errorf("failed to close journal audio: %w", err)
+		}
+	})
+	return err
+}
+
+// run appends the buffered samples to the audio file until the session is closed
+func (s *Session) run() {
+	defer close(s.stopped)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.flush(false)
+		case <-s.done:
+			s.flush(true)
+			return
+		}
+	}
+}
+
+// flush writes the buffered samples; last stops further buffering
+func (s *Session) flush(last bool) {
+	s.mutex.Lock()
+	samples := s.pending
+	s.pending = nil
+	s.closed = last
+	s.mutex.Unlock()
+
+	if len(samples) == 0 {
+		return
+	}
+
+	data := make([]byte, len(samples)*2)
+	for i, sample := range samples {
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(sample))
+	}
+	if _, err := s.file.Write(data); err != nil {
+		platform.GetLogger().Error("Failed to write journal audio: %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestRecovery tests that a session interrupted at any stage is found with its audio
+func TestRecovery(t *testing.T) {
+	dir := t.TempDir()
+	j := New(dir)
+
+	startedAt := time.Date(2026, 3, 1, 10, 0, 0, 0, time.Local)
+	session, err := j.Begin(Entry{ID: "first", StartedAt: startedAt, SampleRate: 16000})
+	if err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+	session.Write([]int16{1, 2, 3})
+	session.Write([]int16{-4})
+
+	// Interrupted while recording: the flushed audio is there
+	time.Sleep(2 * flushInterval)
+	entries, err := New(dir).Unfinished()
+	if err != nil || len(entries) != 1 || entries[0].Stage != StageRecording {
+		t.Fatalf("Unexpected unfinished entries: %+v, %v", entries, err)
+	}
+	if samples, err := j.Load("first"); err != nil || len(samples) != 4 || samples[3] != -4 {
+		t.Errorf("Unexpected audio: %v, %v", samples, err)
+	}
+
+	// Interrupted while transcribing: all audio is written
+	session.Write([]int16{5})
+	if err := session.Transcribing(map[string]string{"window": "0x1a"}); err != nil {
+		t.Fatalf("Transcribing failed: %v", err)
+	}
+	session.Write([]int16{6}) // ignored after the recording stopped
+	entries, _ = j.Unfinished()
+	if len(entries) != 1 || entries[0].Stage != StageTranscribing || entries[0].Context["window"] != "0x1a" {
+		t.Fatalf("Expected a transcribing entry, got %+v", entries)
+	}
+	if samples, _ := j.Load("first"); len(samples) != 5 {
+		t.Errorf("Expected 5 samples, got %v", samples)
+	}
+
+	// A finished session leaves nothing behind
+	if err := session.Finish(); err != nil {
+		t.Fatalf("Finish failed: %v", err)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("Expected an empty journal, got %d files", len(files))
+	}
+
+	// A session that is not journaled can be finished as well
+	var none *Session
+	if err := none.Finish(); err != nil {
+		t.Errorf("Finish of a nil session failed: %v", err)
+	}
+}
+
+// TestLoadTruncated tests that a sample cut in half by a crash is dropped
+func TestLoadTruncated(t *testing.T) {
+	dir := t.TempDir()
+	j := New(dir)
+	if err := os.WriteFile(filepath.Join(dir, "cut.pcm"), []byte{1, 0, 2, 0, 3}, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	samples, err := j.Load("cut")
+	if err != nil || len(samples) != 2 || samples[1] != 2 {
+		t.Errorf("Unexpected audio: %v, %v", samples, err)
+	}
+
+	if err := j.Discard("cut"); err != nil {
+		t.Errorf("Discard failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "cut.pcm")); !os.IsNotExist(err) {
+		t.Error("Expected the audio to be removed")
+	}
+}
+
+// TestUnfinishedOrder tests that leftover sessions are listed oldest first and invalid entries are skipped
+func TestUnfinishedOrder(t *testing.T) {
+	dir := t.TempDir()
+	j := New(dir)
+
+	now := time.Now()
+	for i, id := range []string{"newer", "older"} {
+		session, err := j.Begin(Entry{ID: id, StartedAt: now.Add(-time.Duration(i) * time.Minute), SampleRate: 16000})
+		if err != nil {
+			t.Fatalf("Begin failed: %v", err)
+		}
+		session.Transcribing(nil)
+	}
+	os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0600)
+
+	entries, err := j.Unfinished()
+	if err != nil || len(entries) != 2 || entries[0].ID != "older" {
+		t.Errorf("Unexpected entries: %+v, %v", entries, err)
+	}
+}
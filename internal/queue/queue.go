@@ -0,0 +1,319 @@
+// Package queue keeps recordings whose transcription failed with a retryable
+// error (offline, rate limited, server down) and retries them in the background.
+//
+// Each queued recording is stored as <id>.wav next to <id>.json with the
+// context captured when it was recorded and its retry schedule.
+// A recording whose retry fails permanently (e.g. a rejected API key) is
+// kept, marked failed, and retried once more when the queue is started again.
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/d-mozulyov/vox/internal/platform"
+	"github.com/d-mozulyov/vox/internal/transcription"
+	"github.com/d-mozulyov/vox/internal/wav"
+)
+
+// Retry schedule of queued recordings
+const (
+	initialDelay = 30 * time.Second
+	maxDelay     = 30 * time.Minute
+	pollInterval = 5 * time.Second
+)
+
+// Item describes a queued recording and is stored in its JSON sidecar
+type Item struct {
+	ID         string    `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	Duration   float64   `json:"duration_seconds"`
+	SampleRate int       `json:"sample_rate"`
+	// Window identifies the application that was focused when the recording
+	// stopped, empty if unknown (see inserter.FocusedWindow)
+	Window        string    `json:"window,omitempty"`
+	Attempts      int       `json:"attempts"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	LastError     string    `json:"last_error,omitempty"`
+	// Failed marks a recording whose last attempt failed permanently:
+	// it is not retried until the queue is started again (see Run)
+	Failed bool `json:"failed,omitempty"`
+}
+
+// ProcessFunc transcribes and delivers a queued recording
+// A nil error removes the item from the queue; an error matching
+// transcription.Retryable schedules another attempt, any other error marks the item failed
+type ProcessFunc func(ctx context.Context, item Item, samples []int16) error
+
+// Queue stores failed recordings in a directory until they are transcribed
+type Queue struct {
+	dir      string
+	now      func() time.Time
+	onChange func(length int)
+
+	// mutex serializes access to the files of the queue
+	mutex sync.Mutex
+}
+
+// NewQueue creates a queue in dir
+// onChange is called with the number of queued recordings whenever it changes (may be nil)
+func NewQueue(dir string, onChange func(length int)) *Queue {
+	if onChange == nil {
+		onChange = func(int) {}
+	}
+	return &Queue{
+		dir:      dir,
+		now:      time.Now,
+		onChange: onChange,
+	}
+}
+
+// Add stores a recording with the context captured for it
+// The first retry is scheduled after the initial delay
+func (q *Queue) Add(samples []int16, sampleRate int, item Item, lastErr error) (Item, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if err := os.MkdirAll(q.dir, 0700); err != nil {
+		return item, fmt.Errorf("failed to create queue directory: %w", err)
+	}
+
+	now := q.now()
+	item.ID = now.Format("20060102-150405.000")
+	item.CreatedAt = now
+	item.SampleRate = sampleRate
+	item.Duration = float64(len(samples)) / float64(sampleRate)
+	item.Attempts = 0
+	item.NextAttemptAt = now.Add(initialDelay)
+	if lastErr != nil {
+		item.LastError = lastErr.Error()
+	}
+
+	if err := os.WriteFile(q.path(item.ID, ".wav"), wav.Encode(samples, sampleRate), 0600); err != nil {
+		return item, fmt.Errorf("failed to write queued recording: %w", err)
+	}
+	if err := q.writeItem(item); err != nil {
+		os.Remove(q.path(item.ID, ".wav"))
+		return item, err
+	}
+
+	platform.GetLogger().Info("Recording queued for transcription: %s (%.1f s)", item.ID, item.Duration)
+	q.notifyLocked()
+	return item, nil
+}
+
+// Items returns the queued recordings, oldest first
+func (q *Queue) Items() ([]Item, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.itemsLocked()
+}
+
+// Len returns the number of queued recordings
+func (q *Queue) Len() int {
+	items, err := q.Items()
+	if err != nil {
+		return 0
+	}
+	return len(items)
+}
+
+// Run retries due recordings until ctx is done
+// Recordings are processed one at a time, oldest first. Failed recordings are
+// retried once at the start, as the cause (e.g. the API key) may have been fixed
+func (q *Queue) Run(ctx context.Context, process ProcessFunc) {
+	q.resume()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		q.processDue(ctx, process)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// resume makes the failed recordings due again
+func (q *Queue) resume() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	items, err := q.itemsLocked()
+	if err != nil {
+		platform.GetLogger().Warn("Failed to read transcription queue: %v", err)
+		return
+	}
+	for _, item := range items {
+		if !item.Failed {
+			continue
+		}
+		item.Failed = false
+		item.NextAttemptAt = q.now()
+		if err := q.writeItem(item); err != nil {
+			platform.GetLogger().Warn("Failed to update queued recording: %v", err)
+		}
+	}
+}
+
+// processDue processes every recording whose next attempt is due
+func (q *Queue) processDue(ctx context.Context, process ProcessFunc) {
+	logger := platform.GetLogger()
+
+	items, err := q.Items()
+	if err != nil {
+		logger.Warn("Failed to read transcription queue: %v", err)
+		return
+	}
+
+	for _, item := range items {
+		if ctx.Err() != nil {
+			return
+		}
+		if item.Failed || q.now().Before(item.NextAttemptAt) {
+			continue
+		}
+
+		samples, _, err := q.load(item)
+		if err != nil {
+			logger.Error("Dropping unreadable queued recording %s: %v", item.ID, err)
+			q.remove(item.ID)
+			continue
+		}
+
+		item.Attempts++
+		logger.Info("Retrying queued recording %s (attempt %d)", item.ID, item.Attempts)
+
+		err = process(ctx, item, samples)
+		switch {
+		case err == nil:
+			logger.Info("Queued recording %s transcribed", item.ID)
+			q.remove(item.ID)
+			continue
+		case ctx.Err() != nil:
+			// Stopped while processing: the attempt does not count
+			return
+		case transcription.Retryable(err):
+			item.LastError = err.Error()
+			item.NextAttemptAt = q.now().Add(backoff(item.Attempts))
+			logger.Warn("Queued recording %s failed again: %v. Next attempt at %s",
+				item.ID, err, item.NextAttemptAt.Format(time.TimeOnly))
+		default:
+			// Kept: the recording is what the queue protects, and the cause may be fixed
+			item.LastError = err.Error()
+			item.Failed = true
+			logger.Error("Queued recording %s failed: %v. It is kept in %s and retried on the next start",
+				item.ID, err, q.dir)
+		}
+
+		q.mutex.Lock()
+		if err := q.writeItem(item); err != nil {
+			logger.Warn("Failed to update queued recording: %v", err)
+		}
+		q.mutex.Unlock()
+	}
+}
+
+// backoff returns the delay after the given number of failed attempts:
+// doubling from the initial delay up to the maximum delay
+func backoff(attempts int) time.Duration {
+	delay := initialDelay
+	for i := 1; i < attempts && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxDelay)
+}
+
+// load reads the audio of a queued recording
+func (q *Queue) load(item Item) ([]int16, int, error) {
+	data, err := os.ReadFile(q.path(item.ID, ".wav"))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read queued recording: %w", err)
+	}
+	return wav.Decode(data)
+}
+
+// remove deletes a queued recording and its sidecar
+func (q *Queue) remove(id string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, ext := range []string{".wav", ".json"} {
+		if err := os.Remove(q.path(id, ext)); err != nil && !os.IsNotExist(err) {
+			platform.GetLogger().Warn("Failed to remove queued recording %s: %v", id, err)
+		}
+	}
+	q.notifyLocked()
+}
+
+// itemsLocked reads all sidecars; the caller holds the mutex
+func (q *Queue) itemsLocked() ([]Item, error) {
+	entries, err := os.ReadDir(q.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queue directory: %w", err)
+	}
+
+	var items []Item
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(q.dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read queued item: %w", err)
+		}
+		var item Item
+		if err := json.Unmarshal(data, &item); err != nil {
+			platform.GetLogger().Warn("Skipping invalid queued item %s: %v", entry.Name(), err)
+			continue
+		}
+		item.ID = strings.TrimSuffix(entry.Name(), ".json")
+		items = append(items, item)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].CreatedAt.Before(items[j].CreatedAt)
+	})
+	return items, nil
+}
+
+// writeItem writes the sidecar of a queued recording; the caller holds the mutex
+func (q *Queue) writeItem(item Item) error {
+	data, err := json.MarshalIndent(item, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode queued item: %w", err)
+	}
+	if err := os.WriteFile(q.path(item.ID, ".json"), data, 0600); err != nil {
+		return fmt.Errorf("failed to write queued item: %w", err)
+	}
+	return nil
+}
+
+// notifyLocked reports the queue length; the caller holds the mutex
+func (q *Queue) notifyLocked() {
+	items, err := q.itemsLocked()
+	if err != nil {
+		return
+	}
+	q.onChange(len(items))
+}
+
+// path returns the path of a queued recording's file with the given extension
+func (q *Queue) path(id, ext string) string {
+	return filepath.Join(q.dir, id+ext)
+}
@@ -0,0 +1,146 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/d-mozulyov/vox/internal/transcription"
+)
+
+// newTestQueue creates a queue in a temporary directory with a settable clock
+// Returns the queue, a pointer to its current time and a pointer to the last reported length
+func newTestQueue(t *testing.T) (*Queue, *time.Time, *int) {
+	t.Helper()
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	length := -1
+	q := NewQueue(t.TempDir(), func(n int) { length = n })
+	q.now = func() time.Time { return now }
+	return q, &now, &length
+}
+
+// TestAdd tests that a queued recording is stored with its context
+func TestAdd(t *testing.T) {
+	q, now, length := newTestQueue(t)
+
+	item, err := q.Add(make([]int16, 16000), 16000, Item{Window: "0x1a00003"}, transcription.ErrTemporary)
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if *length != 1 || q.Len() != 1 {
+		t.Errorf("Expected queue length 1, reported %d, Len %d", *length, q.Len())
+	}
+
+	items, err := q.Items()
+	if err != nil {
+		t.Fatalf("Items failed: %v", err)
+	}
+	loaded := items[0]
+	if loaded.ID != item.ID || loaded.Window != "0x1a00003" || loaded.Duration != 1 ||
+		loaded.LastError != "temporary failure" || !loaded.NextAttemptAt.Equal(now.Add(initialDelay)) {
+		t.Errorf("Unexpected item: %+v", loaded)
+	}
+}
+
+// TestProcessDue tests delivery, rescheduling and dropping of queued recordings
+func TestProcessDue(t *testing.T) {
+	q, now, length := newTestQueue(t)
+	ctx := context.Background()
+
+	samples := []int16{1, 2, 3}
+	if _, err := q.Add(samples, 16000, Item{Window: "w"}, nil); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	var calls int
+	var result error
+	process := func(ctx context.Context, item Item, got []int16) error {
+		calls++
+		if item.Window != "w" || len(got) != len(samples) || got[2] != 3 {
+			t.Errorf("Unexpected item %+v with samples %v", item, got)
+		}
+		return result
+	}
+
+	// Not due yet
+	q.processDue(ctx, process)
+	if calls != 0 {
+		t.Fatalf("Processed before the first retry was due")
+	}
+
+	// A temporary failure doubles the delay
+	*now = now.Add(initialDelay)
+	result = fmt.Errorf("offline: %w", transcription.ErrTemporary)
+	q.processDue(ctx, process)
+	items, _ := q.Items()
+	if calls != 1 || len(items) != 1 || items[0].Attempts != 1 || !items[0].NextAttemptAt.Equal(now.Add(initialDelay)) {
+		t.Fatalf("Expected rescheduled item, got %d calls and %+v", calls, items)
+	}
+
+	// Success removes the item
+	*now = now.Add(initialDelay)
+	result = nil
+	q.processDue(ctx, process)
+	if calls != 2 || q.Len() != 0 || *length != 0 {
+		t.Errorf("Expected empty queue after success, got %d calls, length %d", calls, q.Len())
+	}
+
+	// A permanent failure keeps the item, marked failed, and it is not retried
+	if _, err := q.Add(samples, 16000, Item{Window: "w"}, nil); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	*now = now.Add(initialDelay)
+	result = fmt.Errorf("%w: invalid API key", transcription.ErrUnauthorized)
+	q.processDue(ctx, process)
+	items, _ = q.Items()
+	if len(items) != 1 || !items[0].Failed || items[0].LastError == "" {
+		t.Fatalf("Expected the failed item to be kept, got %+v", items)
+	}
+	*now = now.Add(maxDelay)
+	q.processDue(ctx, process)
+	if calls != 3 {
+		t.Errorf("Expected a failed item not to be retried, got %d calls", calls)
+	}
+
+	// Restarting the queue retries it
+	q.resume()
+	result = nil
+	q.processDue(ctx, process)
+	if calls != 4 || q.Len() != 0 {
+		t.Errorf("Expected the failed item to be retried after a restart, got %d calls, length %d", calls, q.Len())
+	}
+}
+
+// TestProcessDueStopped tests that an attempt interrupted by stopping the queue is not counted
+func TestProcessDueStopped(t *testing.T) {
+	q, now, _ := newTestQueue(t)
+	if _, err := q.Add([]int16{1}, 16000, Item{}, nil); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	*now = now.Add(initialDelay)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q.processDue(ctx, func(ctx context.Context, item Item, samples []int16) error {
+		cancel()
+		return ctx.Err()
+	})
+
+	items, _ := q.Items()
+	if len(items) != 1 || items[0].Failed || items[0].Attempts != 0 {
+		t.Errorf("Expected the item unchanged, got %+v", items)
+	}
+}
+
+// TestBackoff tests the retry schedule
+func TestBackoff(t *testing.T) {
+	expected := []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 4 * time.Minute}
+	for i, delay := range expected {
+		if got := backoff(i + 1); got != delay {
+			t.Errorf("backoff(%d) = %v, expected %v", i+1, got, delay)
+		}
+	}
+	if got := backoff(100); got != maxDelay {
+		t.Errorf("backoff(100) = %v, expected %v", got, maxDelay)
+	}
+}
@@ -0,0 +1,39 @@
+package recorder
+
+import "math"
+
+// minLevelDB is the quietest level shown, in dBFS. Anything below is treated as silence
+const minLevelDB = -60.0
+
+// RMS returns the root mean square of 16-bit PCM samples, normalized to 0.0..1.0
+func RMS(samples []int16) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+
+	var sum float64
+	for _, s := range samples {
+		v := float64(s) / 32768
+		sum += v * v
+	}
+
+	return math.Sqrt(sum / float64(len(samples)))
+}
+
+// Level converts an RMS value to an input level from 0.0 to 1.0
+// The scale is logarithmic (dBFS) so that normal speech lands mid-range
+func Level(rms float64) float64 {
+	if rms <= 0 {
+		return 0
+	}
+
+	db := 20 * math.Log10(rms)
+	if db <= minLevelDB {
+		return 0
+	}
+	if db >= 0 {
+		return 1
+	}
+
+	return 1 - db/minLevelDB
+}
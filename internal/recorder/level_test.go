@@ -0,0 +1,52 @@
+package recorder
+
+import (
+	"math"
+	"testing"
+)
+
+// sine generates a sine wave with the given amplitude (0.0 to 1.0)
+func sine(amplitude float64, n int) []int16 {
+	samples := make([]int16, n)
+	for i := range samples {
+		samples[i] = int16(amplitude * 32767 * math.Sin(2*math.Pi*440*float64(i)/SampleRate))
+	}
+	return samples
+}
+
+// TestRMS tests RMS calculation on generated signals
+func TestRMS(t *testing.T) {
+	if rms := RMS(nil); rms != 0 {
+		t.Errorf("Expected 0 for empty input, got %f", rms)
+	}
+	if rms := RMS(make([]int16, 1600)); rms != 0 {
+		t.Errorf("Expected 0 for silence, got %f", rms)
+	}
+
+	// RMS of a full-scale sine wave is 1/sqrt(2)
+	if rms := RMS(sine(1, SampleRate)); math.Abs(rms-1/math.Sqrt2) > 0.01 {
+		t.Errorf("Expected ~0.707 for full-scale sine, got %f", rms)
+	}
+}
+
+// TestLevel tests mapping of RMS values to input levels
+func TestLevel(t *testing.T) {
+	if level := Level(0); level != 0 {
+		t.Errorf("Expected 0 for silence, got %f", level)
+	}
+	if level := Level(1); level != 1 {
+		t.Errorf("Expected 1 for full scale, got %f", level)
+	}
+
+	// -30 dBFS is halfway on a 60 dB scale
+	if level := Level(math.Pow(10, -30.0/20)); math.Abs(level-0.5) > 0.001 {
+		t.Errorf("Expected 0.5 for -30 dBFS, got %f", level)
+	}
+
+	// Louder signals must give higher levels
+	quiet := Level(RMS(sine(0.01, 1600)))
+	loud := Level(RMS(sine(0.5, 1600)))
+	if quiet >= loud {
+		t.Errorf("Expected quiet level %f < loud level %f", quiet, loud)
+	}
+}
@@ -0,0 +1,309 @@
+// Package recorder captures microphone audio using miniaudio (gen2brain/malgo).
+package recorder
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/d-mozulyov/vox/internal/dsp"
+	"github.com/d-mozulyov/vox/internal/platform"
+	"github.com/d-mozulyov/vox/internal/wav"
+	"github.com/gen2brain/malgo"
+)
+
+const (
+	// SampleRate is the capture sample rate in Hz (16 kHz is enough for speech)
+	SampleRate = 16000
+	// Channels is the number of captured channels (mono)
+	Channels = 1
+)
+
+// Recorder defines the interface for capturing microphone audio
+type Recorder interface {
+	// Start begins capturing audio from the default input device
+	// With pre-roll enabled, the buffered audio becomes the start of the recording
+	Start() error
+
+	// Stop ends capturing and returns the recorded 16-bit PCM samples
+	Stop() ([]int16, error)
+
+	// SetPreRoll enables always-on capture that keeps the last duration of
+	// audio in memory and prepends it to the next recording.
+	// A zero duration disables pre-roll, wipes the buffer and releases the device.
+	// The buffer lives in memory only and is never persisted.
+	SetPreRoll(duration time.Duration) error
+
+	// SetLevelCallback registers a callback for input level updates
+	// The callback receives a level from 0.0 (silence) to 1.0 (full scale)
+	// and is called from the audio thread for every captured frame while recording
+	SetLevelCallback(callback func(level float64))
+
+	// SetFrameCallback registers a callback that receives the recorded audio as it
+	// is captured: the pre-roll when recording starts, then every captured frame.
+	// It is called from the audio thread, so it must return quickly and must not
+	// keep the slice. Register it before Start to receive the whole recording.
+	SetFrameCallback(callback func(samples []int16))
+
+	// Close releases the capture device and the audio context
+	Close() error
+}
+
+// recorder implements the Recorder interface
+type recorder struct {
+	context *malgo.AllocatedContext
+	filters *dsp.Chain // applied to every captured frame, used by the audio thread only
+
+	// deviceMutex serializes opening and closing the device.
+	// It is never taken by the audio thread, so the device can be
+	// released while holding it without deadlocking the data callback.
+	device      *malgo.Device
+	deviceMutex sync.Mutex
+
+	// mutex guards the data shared with the audio thread
+	recording     bool
+	samples       []int16
+	preRoll       *ringBuffer // nil when pre-roll is disabled
+	levelCallback func(level float64)
+	frameCallback func(samples []int16)
+	mutex         sync.Mutex
+}
+
+// NewRecorder creates a new recorder instance
+// filters is applied to every captured frame before it is stored; nil disables filtering
+func NewRecorder(filters *dsp.Chain) (Recorder, error) {
+	logger := platform.GetLogger()
+
+	ctx, err := malgo.InitContext(nil, malgo.ContextConfig{}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize capture context: %w", err)
+	}
+
+	if filters == nil {
+		filters = dsp.NewChain()
+	}
+
+	logger.Info("Recorder created successfully (filters: %d)", filters.Len())
+
+	return &recorder{
+		context: ctx,
+		filters: filters,
+	}, nil
+}
+
+// Start begins capturing audio from the default input device
+func (r *recorder) Start() error {
+	r.deviceMutex.Lock()
+	defer r.deviceMutex.Unlock()
+
+	logger := platform.GetLogger()
+
+	r.mutex.Lock()
+	if r.recording {
+		r.mutex.Unlock()
+		return fmt.Errorf("recording is already in progress")
+	}
+	r.samples = make([]int16, 0, SampleRate*10)
+	if r.preRoll != nil {
+		r.samples = append(r.samples, r.preRoll.Samples()...)
+		r.preRoll.Reset()
+	}
+	preRollSamples := len(r.samples)
+	r.recording = true
+	if r.frameCallback != nil && preRollSamples > 0 {
+		r.frameCallback(r.samples)
+	}
+	r.mutex.Unlock()
+
+	if r.device == nil {
+		if err := r.openDevice(); err != nil {
+			r.mutex.Lock()
+			r.recording = false
+			r.samples = nil
+			r.mutex.Unlock()
+			return err
+		}
+	}
+
+	logger.Info("Recording started (pre-roll: %d ms)", preRollSamples*1000/SampleRate)
+
+	return nil
+}
+
+// Stop ends capturing and returns the recorded samples
+func (r *recorder) Stop() ([]int16, error) {
+	r.deviceMutex.Lock()
+	defer r.deviceMutex.Unlock()
+
+	logger := platform.GetLogger()
+
+	r.mutex.Lock()
+	recording := r.recording
+	preRoll := r.preRoll != nil
+	r.mutex.Unlock()
+
+	if !recording {
+		return nil, fmt.Errorf("recording is not in progress")
+	}
+
+	// Without pre-roll the device is released right away
+	if !preRoll {
+		r.closeDevice()
+	}
+
+	r.mutex.Lock()
+	samples := r.samples
+	r.samples = nil
+	r.recording = false
+	r.mutex.Unlock()
+
+	logger.Info("Recording stopped: %d samples (%.1f s)", len(samples), float64(len(samples))/SampleRate)
+
+	return samples, nil
+}
+
+// SetPreRoll enables or disables always-on capture with a pre-roll buffer
+func (r *recorder) SetPreRoll(duration time.Duration) error {
+	r.deviceMutex.Lock()
+	defer r.deviceMutex.Unlock()
+
+	logger := platform.GetLogger()
+
+	r.mutex.Lock()
+	if r.preRoll != nil {
+		r.preRoll.Reset()
+		r.preRoll = nil
+	}
+	if duration > 0 {
+		r.preRoll = newRingBuffer(int(duration.Seconds() * SampleRate))
+	}
+	recording := r.recording
+	r.mutex.Unlock()
+
+	if duration <= 0 {
+		if !recording {
+			r.closeDevice()
+		}
+		logger.Info("Pre-roll disabled, buffer wiped")
+		return nil
+	}
+
+	if r.device == nil {
+		if err := r.openDevice(); err != nil {
+			r.mutex.Lock()
+			r.preRoll = nil
+			r.mutex.Unlock()
+			return err
+		}
+	}
+
+	logger.Info("Pre-roll enabled: microphone stays open, keeping last %v in memory", duration)
+
+	return nil
+}
+
+// SetLevelCallback registers a callback for input level updates
+func (r *recorder) SetLevelCallback(callback func(level float64)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.levelCallback = callback
+}
+
+// SetFrameCallback registers a callback for the recorded audio
+func (r *recorder) SetFrameCallback(callback func(samples []int16)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.frameCallback = callback
+}
+
+// Close releases the capture device and the audio context
+func (r *recorder) Close() error {
+	r.deviceMutex.Lock()
+	defer r.deviceMutex.Unlock()
+
+	// Discard a recording that is still in progress
+	r.closeDevice()
+
+	r.mutex.Lock()
+	if r.preRoll != nil {
+		r.preRoll.Reset()
+		r.preRoll = nil
+	}
+	r.recording = false
+	r.samples = nil
+	r.mutex.Unlock()
+
+	if err := r.context.Uninit(); err != nil {
+		return fmt.Errorf("failed to release capture context: %w", err)
+	}
+	r.context.Free()
+
+	return nil
+}
+
+// openDevice opens and starts the default capture device
+// deviceMutex must be held
+func (r *recorder) openDevice() error {
+	logger := platform.GetLogger()
+
+	// A new stream starts, so filter state from the previous one is dropped
+	r.filters.Reset()
+
+	deviceConfig := malgo.DefaultDeviceConfig(malgo.Capture)
+	deviceConfig.Capture.Format = malgo.FormatS16
+	deviceConfig.Capture.Channels = Channels
+	deviceConfig.SampleRate = SampleRate
+
+	device, err := malgo.InitDevice(r.context.Context, deviceConfig, malgo.DeviceCallbacks{
+		Data: r.onData,
+	})
+	if err != nil {
+		logger.Error("Failed to open capture device: %v", err)
+		return fmt.Errorf("failed to open capture device: %w", err)
+	}
+
+	if err := device.Start(); err != nil {
+		device.Uninit()
+		logger.Error("Failed to start capture device: %v", err)
+		return fmt.Errorf("failed to start capture device: %w", err)
+	}
+
+	r.device = device
+	logger.Info("Capture device opened")
+
+	return nil
+}
+
+// closeDevice releases the capture device if it is open
+// Uninit waits for the data callback to return. deviceMutex must be held
+func (r *recorder) closeDevice() {
+	if r.device == nil {
+		return
+	}
+	r.device.Uninit()
+	r.device = nil
+	platform.GetLogger().Info("Capture device closed")
+}
+
+// onData is called by miniaudio from the audio thread with captured frames
+func (r *recorder) onData(_, input []byte, frameCount uint32) {
+	frame := wav.Samples(input)
+	r.filters.Process(frame)
+
+	r.mutex.Lock()
+	var callback func(level float64)
+	if r.recording {
+		r.samples = append(r.samples, frame...)
+		callback = r.levelCallback
+		if r.frameCallback != nil {
+			r.frameCallback(frame)
+		}
+	} else if r.preRoll != nil {
+		r.preRoll.Write(frame)
+	}
+	r.mutex.Unlock()
+
+	if callback != nil {
+		callback(Level(RMS(frame)))
+	}
+}
@@ -0,0 +1,58 @@
+package recorder
+
+// ringBuffer keeps the most recent samples in a fixed-size circular buffer
+type ringBuffer struct {
+	data []int16
+	pos  int  // next write position
+	full bool // true once the buffer has wrapped around
+}
+
+// newRingBuffer creates a ring buffer holding up to size samples
+func newRingBuffer(size int) *ringBuffer {
+	return &ringBuffer{
+		data: make([]int16, size),
+	}
+}
+
+// Write appends samples, overwriting the oldest ones when the buffer is full
+func (rb *ringBuffer) Write(samples []int16) {
+	if len(rb.data) == 0 {
+		return
+	}
+
+	// Only the tail of a large write fits into the buffer
+	if len(samples) >= len(rb.data) {
+		copy(rb.data, samples[len(samples)-len(rb.data):])
+		rb.pos = 0
+		rb.full = true
+		return
+	}
+
+	n := copy(rb.data[rb.pos:], samples)
+	if n < len(samples) {
+		copy(rb.data, samples[n:])
+		rb.full = true
+	}
+	rb.pos = (rb.pos + len(samples)) % len(rb.data)
+	if rb.pos == 0 {
+		rb.full = true
+	}
+}
+
+// Samples returns a copy of the buffered samples, oldest first
+func (rb *ringBuffer) Samples() []int16 {
+	if !rb.full {
+		return append([]int16(nil), rb.data[:rb.pos]...)
+	}
+
+	samples := make([]int16, 0, len(rb.data))
+	samples = append(samples, rb.data[rb.pos:]...)
+	return append(samples, rb.data[:rb.pos]...)
+}
+
+// Reset wipes the buffered audio
+func (rb *ringBuffer) Reset() {
+	clear(rb.data)
+	rb.pos = 0
+	rb.full = false
+}
@@ -0,0 +1,39 @@
+package recorder
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestRingBuffer tests ordering and wrap-around of the pre-roll buffer
+func TestRingBuffer(t *testing.T) {
+	rb := newRingBuffer(4)
+
+	rb.Write([]int16{1, 2})
+	if s := rb.Samples(); !slices.Equal(s, []int16{1, 2}) {
+		t.Errorf("Expected [1 2], got %v", s)
+	}
+
+	// Wrap around: oldest samples are overwritten
+	rb.Write([]int16{3, 4, 5})
+	if s := rb.Samples(); !slices.Equal(s, []int16{2, 3, 4, 5}) {
+		t.Errorf("Expected [2 3 4 5], got %v", s)
+	}
+
+	// A write larger than the buffer keeps only its tail
+	rb.Write([]int16{6, 7, 8, 9, 10, 11})
+	if s := rb.Samples(); !slices.Equal(s, []int16{8, 9, 10, 11}) {
+		t.Errorf("Expected [8 9 10 11], got %v", s)
+	}
+
+	// Reset wipes the audio
+	rb.Reset()
+	if s := rb.Samples(); len(s) != 0 {
+		t.Errorf("Expected empty buffer after reset, got %v", s)
+	}
+	for _, v := range rb.data {
+		if v != 0 {
+			t.Fatalf("Expected buffer memory to be zeroed, got %v", rb.data)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+// Package recordings saves recorded audio with a JSON sidecar for debugging and replay.
+//
+// Each recording is stored as <id>.wav next to <id>.json in the store directory.
+// Old recordings are removed by age and total size after every save.
+package recordings
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/d-mozulyov/vox/internal/platform"
+	"github.com/d-mozulyov/vox/internal/wav"
+)
+
+// Metadata describes a saved recording and is stored in its JSON sidecar
+// Fields that are not known yet (e.g. transcript before transcription) are omitted
+type Metadata struct {
+	ID         string            `json:"id"`
+	StartedAt  time.Time         `json:"started_at"`
+	Duration   float64           `json:"duration_seconds"`
+	SampleRate int               `json:"sample_rate"`
+	Profile    string            `json:"profile,omitempty"` // provider preset, empty for a custom provider
+	Context    map[string]string `json:"context,omitempty"`
+	Backend    string            `json:"backend,omitempty"`
+	Transcript string            `json:"transcript,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	LatencyMs  int64             `json:"latency_ms,omitempty"`
+}
+
+// Store saves recordings to a directory and applies retention limits
+type Store struct {
+	dir      string
+	maxBytes int64         // 0 means unlimited
+	maxAge   time.Duration // 0 means unlimited
+}
+
+// NewStore creates a store in dir that keeps at most maxSizeMB megabytes
+// of recordings no older than maxAgeDays days (0 disables a limit)
+func NewStore(dir string, maxSizeMB, maxAgeDays int) *Store {
+	return &Store{
+		dir:      dir,
+		maxBytes: int64(maxSizeMB) * 1024 * 1024,
+		maxAge:   time.Duration(maxAgeDays) * 24 * time.Hour,
+	}
+}
+
+// Save writes the recording and its metadata, then applies retention
+// Returns the metadata completed with the audio format, for later updates
+func (s *Store) Save(samples []int16, sampleRate int, meta Metadata) (Metadata, error) {
+	logger := platform.GetLogger()
+
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return meta, fmt.Errorf("failed to create recordings directory: %w", err)
+	}
+
+	meta.SampleRate = sampleRate
+	meta.Duration = float64(len(samples)) / float64(sampleRate)
+
+	wavPath := filepath.Join(s.dir, meta.ID+".wav")
+	if err := os.WriteFile(wavPath, wav.Encode(samples, sampleRate), 0600); err != nil {
+		return meta, fmt.Errorf("failed to write recording: %w", err)
+	}
+
+	if err := s.UpdateMetadata(meta); err != nil {
+		return meta, err
+	}
+
+	logger.Info("Recording saved: %s (%.1f s)", wavPath, meta.Duration)
+
+	// The new recording is kept: its sidecar is updated once it is transcribed
+	if err := s.cleanup(meta.ID); err != nil {
+		logger.Warn("Failed to apply recordings retention: %v", err)
+	}
+
+	return meta, nil
+}
+
+// UpdateMetadata rewrites the JSON sidecar of a saved recording
+// Used to add the transcript, backend and latency once they are known
+func (s *Store) UpdateMetadata(meta Metadata) error {
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode recording metadata: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(s.dir, meta.ID+".json"), data, 0600); err != nil {
+		return fmt.Errorf("failed to write recording metadata: %w", err)
+	}
+
+	return nil
+}
+
+// Cleanup removes recordings older than the age limit, then the oldest
+// recordings until the total size fits the size limit
+func (s *Store) Cleanup() error {
+	return s.cleanup("")
+}
+
+// cleanup is Cleanup that never removes the recording keep
+func (s *Store) cleanup(keep string) error {
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read recordings directory: %w", err)
+	}
+
+	type recording struct {
+		id      string
+		size    int64
+		modTime time.Time
+	}
+
+	// Group files by ID: the WAV and its sidecar are removed together
+	byID := make(map[string]*recording)
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".wav" && ext != ".json") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		id := strings.TrimSuffix(entry.Name(), ext)
+		rec, ok := byID[id]
+		if !ok {
+			rec = &recording{id: id}
+			byID[id] = rec
+		}
+		rec.size += info.Size()
+		if info.ModTime().After(rec.modTime) {
+			rec.modTime = info.ModTime()
+		}
+	}
+
+	list := make([]*recording, 0, len(byID))
+	var total int64
+	for _, rec := range byID {
+		list = append(list, rec)
+		total += rec.size
+	}
+
+	// Oldest first
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].modTime.Before(list[j].modTime)
+	})
+
+	now := time.Now()
+	for _, rec := range list {
+		if rec.id == keep {
+			continue
+		}
+		expired := s.maxAge > 0 && now.Sub(rec.modTime) > s.maxAge
+		oversize := s.maxBytes > 0 && total > s.maxBytes
+		if !expired && !oversize {
+			break
+		}
+		if err := s.remove(rec.id); err != nil {
+			return err
+		}
+		total -= rec.size
+	}
+
+	return nil
+}
+
+// remove deletes a recording and its sidecar
+func (s *Store) remove(id string) error {
+	for _, ext := range []string{".wav", ".json"} {
+		if err := os.Remove(filepath.Join(s.dir, id+ext)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove recording %s: %w", id, err)
+		}
+	}
+	platform.GetLogger().Info("Recording removed by retention: %s", id)
+	return nil
+}
@@ -0,0 +1,110 @@
+package recordings
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/d-mozulyov/vox/internal/session"
+)
+
+// TestSave tests that a recording is written with its sidecar
+func TestSave(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(dir, 0, 0)
+
+	startedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	meta, err := store.Save(make([]int16, 16000), 16000, Metadata{ID: session.New(startedAt).ID, StartedAt: startedAt})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, meta.ID+".wav")); err != nil {
+		t.Errorf("WAV file not written: %v", err)
+	}
+
+	// Add the transcript after transcription
+	meta.Transcript = "hello"
+	meta.LatencyMs = 420
+	if err := store.UpdateMetadata(meta); err != nil {
+		t.Fatalf("UpdateMetadata failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, meta.ID+".json"))
+	if err != nil {
+		t.Fatalf("Sidecar not written: %v", err)
+	}
+	var loaded Metadata
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatalf("Invalid sidecar: %v", err)
+	}
+	if loaded.Transcript != "hello" || loaded.LatencyMs != 420 || loaded.Duration != 1 {
+		t.Errorf("Unexpected metadata: %+v", loaded)
+	}
+}
+
+// writeRecording creates a fake recording of the given size and age
+func writeRecording(t *testing.T, dir, id string, size int, age time.Duration) {
+	t.Helper()
+	modTime := time.Now().Add(-age)
+	for _, name := range []string{id + ".wav", id + ".json"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, make([]byte, size/2), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// exists reports whether a recording is still present
+func exists(dir, id string) bool {
+	_, err := os.Stat(filepath.Join(dir, id+".wav"))
+	return err == nil
+}
+
+// TestSaveKeepsNewRecording tests that retention after a save never removes the recording just saved
+func TestSaveKeepsNewRecording(t *testing.T) {
+	dir := t.TempDir()
+	writeRecording(t, dir, "old", 512*1024, time.Hour)
+	store := NewStore(dir, 1, 0)
+
+	// 1.5 MB of audio alone exceeds the 1 MB limit
+	startedAt := time.Now()
+	meta, err := store.Save(make([]int16, 768*1024), 16000, Metadata{ID: session.New(startedAt).ID, StartedAt: startedAt})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if !exists(dir, meta.ID) {
+		t.Error("Expected the new recording to be kept")
+	}
+	if exists(dir, "old") {
+		t.Error("Expected the older recording to be removed to fit the size")
+	}
+}
+
+// TestCleanup tests age- and size-based retention
+func TestCleanup(t *testing.T) {
+	dir := t.TempDir()
+	writeRecording(t, dir, "old", 1024, 10*24*time.Hour)
+	writeRecording(t, dir, "a", 512*1024, 3*time.Hour)
+	writeRecording(t, dir, "b", 512*1024, 2*time.Hour)
+	writeRecording(t, dir, "c", 512*1024, 1*time.Hour)
+
+	// 1 MB limit, 7 days: "old" expires, "a" is removed to fit the size
+	if err := NewStore(dir, 1, 7).Cleanup(); err != nil {
+		t.Fatalf("Cleanup failed: %v", err)
+	}
+
+	for id, want := range map[string]bool{"old": false, "a": false, "b": true, "c": true} {
+		if got := exists(dir, id); got != want {
+			t.Errorf("Recording %s: expected exists=%v, got %v", id, want, got)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a.json")); !os.IsNotExist(err) {
+		t.Error("Expected sidecar to be removed with its recording")
+	}
+}
@@ -0,0 +1,169 @@
+// Package session describes one dictation on its way through the pipeline:
+// recorded, transcribed and inserted.
+//
+// The state machine starts a session when recording starts and passes it with
+// every state change, so the recorder, transcription, inserter, history and
+// indicators all work on the same session instead of shared variables.
+// A session is safe for concurrent use.
+package session
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// IDLayout is the time format of session IDs (sortable, filename-safe)
+const IDLayout = "20060102-150405.000"
+
+// Context keys captured by the pipeline
+const (
+	// ContextWindow is the window focused when the recording stopped (see inserter.FocusedWindow)
+	ContextWindow = "window"
+)
+
+// Session is one recording and its transcription
+type Session struct {
+	ID        string
+	StartedAt time.Time // when recording started
+
+	mutex      sync.Mutex
+	profile    string
+	values     map[string]string // captured context
+	stoppedAt  time.Time
+	samples    []int16
+	sampleRate int
+	backend    string
+	transcript string
+	err        error
+	queued     bool
+	cancel     context.CancelFunc
+	cancelled  bool
+}
+
+// New creates a session whose recording started at startedAt
+func New(startedAt time.Time) *Session {
+	return &Session{
+		ID:        startedAt.Format(IDLayout),
+		StartedAt: startedAt,
+		values:    make(map[string]string),
+	}
+}
+
+// SetProfile sets the name of the settings profile used for the session: the provider preset
+func (s *Session) SetProfile(profile string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.profile = profile
+}
+
+// Profile returns the name of the settings profile, empty for a custom provider
+func (s *Session) Profile() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.profile
+}
+
+// SetContext stores a value of the captured context, e.g. ContextWindow
+func (s *Session) SetContext(key, value string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.values[key] = value
+}
+
+// Context returns a copy of the captured context
+func (s *Session) Context() map[string]string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	values := make(map[string]string, len(s.values))
+	for key, value := range s.values {
+		values[key] = value
+	}
+	return values
+}
+
+// ContextValue returns a value of the captured context, empty if not captured
+func (s *Session) ContextValue(key string) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.values[key]
+}
+
+// SetAudio stores the finished recording
+func (s *Session) SetAudio(samples []int16, sampleRate int, stoppedAt time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.samples = samples
+	s.sampleRate = sampleRate
+	s.stoppedAt = stoppedAt
+}
+
+// Audio returns the recording, nil until it is stopped
+func (s *Session) Audio() (samples []int16, sampleRate int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.samples, s.sampleRate
+}
+
+// RecordingDuration returns the time from the start to the stop of the recording
+// Returns 0 while recording
+func (s *Session) RecordingDuration() time.Duration {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.stoppedAt.IsZero() {
+		return 0
+	}
+	return s.stoppedAt.Sub(s.StartedAt)
+}
+
+// SetResult stores the outcome of the transcription
+// On error, transcript holds the text inserted before the failure, if any
+func (s *Session) SetResult(backend, transcript string, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.backend = backend
+	s.transcript = transcript
+	s.err = err
+}
+
+// Result returns the outcome of the transcription, empty until it is finished
+func (s *Session) Result() (backend, transcript string, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.backend, s.transcript, s.err
+}
+
+// SetQueued marks the recording as kept in the offline queue for a later attempt
+func (s *Session) SetQueued() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.queued = true
+}
+
+// Queued reports whether the recording was kept in the offline queue after a failure
+func (s *Session) Queued() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.queued
+}
+
+// SetCancel sets the function that aborts the session's transcription
+// If Cancel was already called, cancel is called right away
+func (s *Session) SetCancel(cancel context.CancelFunc) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.cancel = cancel
+	if s.cancelled && cancel != nil {
+		cancel()
+	}
+}
+
+// Cancel aborts the transcription, now or as soon as it starts
+func (s *Session) Cancel() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.cancelled = true
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
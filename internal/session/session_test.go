@@ -0,0 +1,74 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestSession tests the data collected along the pipeline
+func TestSession(t *testing.T) {
+	startedAt := time.Date(2026, 3, 1, 10, 20, 30, 400_000_000, time.Local)
+	s := New(startedAt)
+	if s.ID != "20260301-102030.400" {
+		t.Errorf("Unexpected ID: %s", s.ID)
+	}
+	if s.RecordingDuration() != 0 {
+		t.Error("Expected no duration while recording")
+	}
+
+	s.SetContext(ContextWindow, "0x1a")
+	values := s.Context()
+	values[ContextWindow] = "changed"
+	if s.ContextValue(ContextWindow) != "0x1a" {
+		t.Error("Expected Context to return a copy")
+	}
+
+	s.SetAudio(make([]int16, 16000), 16000, startedAt.Add(1500*time.Millisecond))
+	if samples, rate := s.Audio(); len(samples) != 16000 || rate != 16000 {
+		t.Errorf("Unexpected audio: %d samples at %d Hz", len(samples), rate)
+	}
+	if s.RecordingDuration() != 1500*time.Millisecond {
+		t.Errorf("Unexpected duration: %v", s.RecordingDuration())
+	}
+
+	failure := errors.New("offline")
+	s.SetResult("chat", "partial", failure)
+	if backend, transcript, err := s.Result(); backend != "chat" || transcript != "partial" || err != failure {
+		t.Errorf("Unexpected result: %q %q %v", backend, transcript, err)
+	}
+
+	if s.Queued() {
+		t.Error("Expected a new session not to be queued")
+	}
+	s.SetQueued()
+	if !s.Queued() {
+		t.Error("Expected the session to be queued")
+	}
+}
+
+// TestCancel tests that a cancel requested before the transcription starts is not lost
+func TestCancel(t *testing.T) {
+	s := New(time.Now())
+	s.Cancel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.SetCancel(cancel)
+	if ctx.Err() == nil {
+		t.Error("Expected the transcription to be cancelled when it starts")
+	}
+
+	s = New(time.Now())
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	s.SetCancel(cancel)
+	if ctx.Err() != nil {
+		t.Fatal("Unexpected cancel")
+	}
+	s.Cancel()
+	if ctx.Err() == nil {
+		t.Error("Expected the running transcription to be cancelled")
+	}
+}
@@ -0,0 +1,30 @@
+package state
+
+import "time"
+
+// Clock provides the time and timers to the state machine
+// Tests inject a fake clock to drive state timeouts deterministically
+type Clock interface {
+	Now() time.Time
+	// AfterFunc calls f in its own goroutine after the duration
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a pending call created by Clock.AfterFunc
+type Timer interface {
+	// Stop prevents the call, returns false if it already happened or was stopped
+	Stop() bool
+}
+
+// systemClock is the Clock of the real time
+type systemClock struct{}
+
+// Now returns the current time
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// AfterFunc calls f after the duration using time.AfterFunc
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
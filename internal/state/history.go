@@ -0,0 +1,64 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// historySize is the maximum number of entries kept in the history
+const historySize = 100
+
+// HistoryEntry records a transition, or a rejected transition attempt
+type HistoryEntry struct {
+	Time     time.Time
+	From     State
+	To       State
+	Event    Event         // the event fired, empty for a direct Transition
+	Session  string        // ID of the session of the transition, empty outside a session
+	Duration time.Duration // time spent in From before the transition, 0 if rejected
+	Rejected bool          // the transition was invalid and did not happen
+}
+
+// History is a snapshot of the state machine's recent transitions
+type History struct {
+	// Entries holds the most recent transitions and rejected attempts, oldest first
+	Entries []HistoryEntry
+	// Current is the current state, entered at Since
+	Current State
+	Since   time.Time
+	// TimeInState holds the total time spent in each state since the machine
+	// was created, including the current state up to the snapshot
+	TimeInState map[State]time.Duration
+}
+
+// String formats the history for diagnostics, one entry per line
+func (h History) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Current state: %s since %s\n", h.Current, h.Since.Format(time.RFC3339Nano))
+
+	for _, state := range []State{StateIdle, StateRecording, StateTranscribing, StateError} {
+		fmt.Fprintf(&b, "Time in %s: %v\n", state, h.TimeInState[state].Round(time.Millisecond))
+	}
+
+	for _, entry := range h.Entries {
+		event := ""
+		if entry.Event != "" {
+			event = " on " + string(entry.Event)
+		}
+		switch {
+		case entry.Rejected && entry.Event != "":
+			fmt.Fprintf(&b, "%s %s ignored in %s\n", entry.Time.Format(time.RFC3339Nano), entry.Event, entry.From)
+		case entry.Rejected:
+			fmt.Fprintf(&b, "%s %s -> %s rejected\n", entry.Time.Format(time.RFC3339Nano), entry.From, entry.To)
+		default:
+			session := ""
+			if entry.Session != "" {
+				session = " [session " + entry.Session + "]"
+			}
+			fmt.Fprintf(&b, "%s %s -> %s%s after %v%s\n", entry.Time.Format(time.RFC3339Nano), entry.From, entry.To, event, entry.Duration.Round(time.Millisecond), session)
+		}
+	}
+
+	return b.String()
+}
@@ -3,23 +3,49 @@ package state
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/d-mozulyov/vox/internal/platform"
+	"github.com/d-mozulyov/vox/internal/session"
 )
 
 // stateMachine is the concrete implementation of StateMachine interface
 type stateMachine struct {
-	current   State
-	mutex     sync.RWMutex
-	callbacks []func(oldState, newState State)
+	table       Table
+	current     State
+	mutex       sync.RWMutex
+	subscribers []*subscriber
+	seq         uint64           // number of the last accepted transition
+	session     *session.Session // current session, nil in StateIdle
+	clock       Clock
+	timer       Timer // timeout of the current state, nil if it has none
+
+	// Transition history, see History
+	since       time.Time // when the current state was entered
+	history     []HistoryEntry
+	timeInState map[State]time.Duration // totals of the states left so far
 }
 
-// NewStateMachine creates a new state machine initialized to StateIdle
+// NewStateMachine creates a new state machine with DefaultTable initialized to StateIdle
 func NewStateMachine() StateMachine {
-	return &stateMachine{
-		current:   StateIdle,
-		callbacks: make([]func(oldState, newState State), 0),
+	return NewStateMachineFromTable(DefaultTable(), nil)
+}
+
+// NewStateMachineFromTable creates a new state machine with the given table initialized to StateIdle
+// The clock drives history and timeouts; nil uses the system clock
+func NewStateMachineFromTable(table Table, clock Clock) StateMachine {
+	if clock == nil {
+		clock = systemClock{}
 	}
+	sm := &stateMachine{
+		table:       table,
+		current:     StateIdle,
+		clock:       clock,
+		timeInState: make(map[State]time.Duration),
+	}
+	sm.since = clock.Now()
+	sm.startTimer()
+	return sm
 }
 
 // GetState returns the current application state
@@ -30,63 +56,189 @@ func (sm *stateMachine) GetState() State {
 }
 
 // Transition attempts to transition to a new state
-// Returns error if the transition is invalid
+// Returns error if no rule of the table allows the transition
 func (sm *stateMachine) Transition(newState State) error {
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 
-	logger := platform.GetLogger()
-
-	// Validate the transition
-	if !sm.isValidTransition(sm.current, newState) {
-		err := fmt.Errorf("invalid state transition: %s -> %s", sm.current, newState)
-		logger.Error("Invalid state transition attempted: %s -> %s", sm.current, newState)
-		return err
+	rule, ok := sm.table.find(sm.current, func(rule Rule) bool { return rule.To == newState })
+	if !ok {
+		platform.GetLogger().Error("Invalid state transition attempted: %s -> %s", sm.current, newState)
+		sm.record(HistoryEntry{Time: sm.clock.Now(), From: sm.current, To: newState, Rejected: true})
+		return fmt.Errorf("invalid state transition: %s -> %s", sm.current, newState)
 	}
 
-	oldState := sm.current
-	sm.current = newState
+	sm.enter(rule, "")
+	return nil
+}
 
-	logger.Info("State transition: %s -> %s", oldState, newState)
+// Fire moves the machine by the first rule for the event from the current state
+// Returns ErrNoTransition if no rule applies; the state is then unchanged
+func (sm *stateMachine) Fire(event Event) error {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	return sm.fire(event)
+}
 
-	// Notify all subscribers (outside the lock to avoid deadlocks)
-	sm.mutex.Unlock()
-	sm.notifySubscribers(oldState, newState)
+// FireFor fires the event if sess is the current session
+func (sm *stateMachine) FireFor(sess *session.Session, event Event) error {
 	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	if sess == nil || sm.session != sess {
+		platform.GetLogger().Info("Event %s of an ended session ignored in %s state", event, sm.current)
+		sm.record(HistoryEntry{Time: sm.clock.Now(), From: sm.current, To: sm.current, Event: event, Rejected: true})
+		return fmt.Errorf("%w for %s: the session has ended", ErrNoTransition, event)
+	}
+	return sm.fire(event)
+}
+
+// fire is Fire with the mutex held by the caller
+func (sm *stateMachine) fire(event Event) error {
+	rule, ok := sm.table.find(sm.current, func(rule Rule) bool { return rule.Event == event })
+	if !ok {
+		platform.GetLogger().Info("Event %s ignored in %s state", event, sm.current)
+		sm.record(HistoryEntry{Time: sm.clock.Now(), From: sm.current, To: sm.current, Event: event, Rejected: true})
+		return fmt.Errorf("%w for %s in %s state", ErrNoTransition, event, sm.current)
+	}
 
+	sm.enter(rule, event)
 	return nil
 }
 
+// enter performs the transition of a rule: runs the hooks, records it and notifies subscribers
+// The caller holds the mutex
+func (sm *stateMachine) enter(rule Rule, event Event) {
+	now := sm.clock.Now()
+	oldState, newState := sm.current, rule.To
+	if rule.Start {
+		sm.session = session.New(now)
+	}
+	current := sm.session
+	sessionID := ""
+	if current != nil {
+		sessionID = current.ID
+	}
+
+	if hook := sm.table.OnExit[oldState]; hook != nil {
+		hook(oldState, newState)
+	}
+
+	duration := now.Sub(sm.since)
+	sm.current = newState
+	sm.since = now
+	sm.timeInState[oldState] += duration
+	sm.record(HistoryEntry{Time: now, From: oldState, To: newState, Event: event, Duration: duration, Session: sessionID})
+
+	if event != "" {
+		platform.GetLogger().Info("State transition: %s -> %s on %s", oldState, newState, event)
+	} else {
+		platform.GetLogger().Info("State transition: %s -> %s", oldState, newState)
+	}
+
+	if hook := sm.table.OnEnter[newState]; hook != nil {
+		hook(oldState, newState)
+	}
+
+	// Queue the change for every subscriber under the lock, so concurrent
+	// transitions reach each subscriber in the order they happened
+	sm.seq++
+	change := Change{Seq: sm.seq, Time: now, From: oldState, To: newState, Event: event, Session: current}
+	for _, s := range sm.subscribers {
+		s.enqueue(change)
+	}
+
+	// The session ends with the return to Idle; this change still carries it
+	if newState == StateIdle {
+		sm.session = nil
+	}
+
+	sm.startTimer()
+}
+
+// startTimer arms the timeout of the current state, replacing the previous one
+// The caller holds the mutex (or owns the machine during construction)
+func (sm *stateMachine) startTimer() {
+	if sm.timer != nil {
+		sm.timer.Stop()
+		sm.timer = nil
+	}
+
+	timeout := sm.table.Timeouts[sm.current]
+	if timeout <= 0 || !sm.table.handles(sm.current, EventTimeout) {
+		return
+	}
+
+	// A timer that fires after the state was left, but before it was
+	// stopped, sees a different seq and does nothing
+	seq := sm.seq
+	sm.timer = sm.clock.AfterFunc(timeout, func() {
+		sm.mutex.Lock()
+		defer sm.mutex.Unlock()
+		if sm.seq != seq {
+			return
+		}
+		platform.GetLogger().Info("%s state timed out after %v", sm.current, timeout)
+		sm.fire(EventTimeout)
+	})
+}
+
 // Subscribe registers a callback for state changes
-func (sm *stateMachine) Subscribe(callback func(oldState, newState State)) {
+// Each callback runs on its own goroutine and receives changes in transition order
+func (sm *stateMachine) Subscribe(callback func(change Change)) Subscription {
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
-	sm.callbacks = append(sm.callbacks, callback)
+
+	s := newSubscriber(sm, callback)
+	sm.subscribers = append(sm.subscribers, s)
+	return s
 }
 
-// isValidTransition checks if a state transition is valid
-// Valid transitions:
-// - Idle -> Recording (start recording via hotkey)
-// - Recording -> Idle (stop recording via hotkey)
-func (sm *stateMachine) isValidTransition(from, to State) bool {
-	switch from {
-	case StateIdle:
-		return to == StateRecording
-	case StateRecording:
-		return to == StateIdle
-	default:
-		return false
+// removeSubscriber stops queueing changes for a subscriber
+func (sm *stateMachine) removeSubscriber(target *subscriber) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	for i, s := range sm.subscribers {
+		if s == target {
+			sm.subscribers = append(sm.subscribers[:i:i], sm.subscribers[i+1:]...)
+			return
+		}
 	}
 }
 
-// notifySubscribers calls all registered callbacks with the state change
-func (sm *stateMachine) notifySubscribers(oldState, newState State) {
+// Session returns the current session, nil in the Idle state
+func (sm *stateMachine) Session() *session.Session {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+	return sm.session
+}
+
+// History returns a snapshot of the recent transitions and the time spent in each state
+func (sm *stateMachine) History() History {
 	sm.mutex.RLock()
-	callbacks := make([]func(oldState, newState State), len(sm.callbacks))
-	copy(callbacks, sm.callbacks)
-	sm.mutex.RUnlock()
+	defer sm.mutex.RUnlock()
+
+	timeInState := make(map[State]time.Duration, len(sm.timeInState)+1)
+	for state, duration := range sm.timeInState {
+		timeInState[state] = duration
+	}
+	timeInState[sm.current] += sm.clock.Now().Sub(sm.since)
+
+	return History{
+		Entries:     append([]HistoryEntry(nil), sm.history...),
+		Current:     sm.current,
+		Since:       sm.since,
+		TimeInState: timeInState,
+	}
+}
 
-	for _, callback := range callbacks {
-		callback(oldState, newState)
+// record appends an entry to the history, dropping the oldest beyond historySize
+// The caller holds the mutex
+func (sm *stateMachine) record(entry HistoryEntry) {
+	if len(sm.history) == historySize {
+		copy(sm.history, sm.history[1:])
+		sm.history = sm.history[:historySize-1]
 	}
+	sm.history = append(sm.history, entry)
 }
@@ -1,6 +1,12 @@
 package state
 
-import "testing"
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
 
 // TestStateMachine tests basic state machine functionality
 func TestStateMachine(t *testing.T) {
@@ -23,25 +29,454 @@ func TestStateMachine(t *testing.T) {
 	if err := sm.Transition(StateIdle); err == nil {
 		t.Error("Expected error for invalid Idle->Idle transition")
 	}
+
+	// Test transcription flow: Idle -> Recording -> Transcribing -> Idle
+	if err := sm.Transition(StateRecording); err != nil {
+		t.Errorf("Idle->Recording failed: %v", err)
+	}
+	if err := sm.Transition(StateTranscribing); err != nil {
+		t.Errorf("Recording->Transcribing failed: %v", err)
+	}
+	if err := sm.Transition(StateRecording); err == nil {
+		t.Error("Expected error for invalid Transcribing->Recording transition")
+	}
+	if err := sm.Transition(StateIdle); err != nil {
+		t.Errorf("Transcribing->Idle failed: %v", err)
+	}
+}
+
+// TestFire tests that events move the machine by the default table
+func TestFire(t *testing.T) {
+	sm := NewStateMachine()
+
+	steps := []struct {
+		event    Event
+		expected State
+		ignored  bool
+	}{
+		{EventToggle, StateRecording, false},
+		{EventCancel, StateIdle, false},
+		{EventCancel, StateIdle, true},
+		{EventToggle, StateRecording, false},
+		{EventToggle, StateTranscribing, false},
+		{EventToggle, StateTranscribing, true},
+		{EventFinish, StateIdle, false},
+	}
+	for i, step := range steps {
+		err := sm.Fire(step.event)
+		if ignored := errors.Is(err, ErrNoTransition); ignored != step.ignored {
+			t.Errorf("Step %d: %s returned %v", i, step.event, err)
+		}
+		if sm.GetState() != step.expected {
+			t.Errorf("Step %d: expected %s after %s, got %s", i, step.expected, step.event, sm.GetState())
+		}
+	}
+
+	history := sm.History()
+	if entry := history.Entries[2]; !entry.Rejected || entry.Event != EventCancel {
+		t.Errorf("Expected the ignored Cancel in the history, got %+v", entry)
+	}
+}
+
+// TestTable tests guards and entry/exit hooks of a custom table
+func TestTable(t *testing.T) {
+	allowed := false
+	var calls []string
+	hook := func(name string) Hook {
+		return func(from, to State) {
+			calls = append(calls, fmt.Sprintf("%s %s->%s", name, from, to))
+		}
+	}
+
+	sm := NewStateMachineFromTable(Table{
+		Rules: []Rule{
+			{From: StateIdle, Event: EventToggle, To: StateRecording, Guard: func() bool { return allowed }},
+			{From: StateIdle, Event: EventToggle, To: StateTranscribing},
+		},
+	}, nil)
+
+	// The guarded rule is skipped, so the next rule for the event applies
+	if err := sm.Transition(StateRecording); err == nil {
+		t.Error("Expected the guard to reject Idle->Recording")
+	}
+	sm.Fire(EventToggle)
+	if sm.GetState() != StateTranscribing {
+		t.Fatalf("Expected the unguarded rule to apply, got %s", sm.GetState())
+	}
+
+	sm = NewStateMachineFromTable(Table{
+		Rules:   []Rule{{From: StateIdle, Event: EventToggle, To: StateRecording, Guard: func() bool { return allowed }}},
+		OnExit:  map[State]Hook{StateIdle: hook("exit")},
+		OnEnter: map[State]Hook{StateRecording: hook("enter")},
+	}, nil)
+	allowed = true
+	if err := sm.Fire(EventToggle); err != nil {
+		t.Fatalf("Fire failed: %v", err)
+	}
+	expected := []string{"exit Idle->Recording", "enter Idle->Recording"}
+	if fmt.Sprint(calls) != fmt.Sprint(expected) {
+		t.Errorf("Expected hooks %v, got %v", expected, calls)
+	}
+}
+
+// receive waits for a state change sent by a subscriber
+func receive(t *testing.T, changes <-chan [2]State) [2]State {
+	t.Helper()
+	select {
+	case change := <-changes:
+		return change
+	case <-time.After(time.Second):
+		t.Fatal("Callback was not called")
+		return [2]State{}
+	}
 }
 
 // TestSubscribe tests the subscription mechanism
 func TestSubscribe(t *testing.T) {
 	sm := NewStateMachine()
 
-	var called bool
-	sm.Subscribe(func(oldState, newState State) {
-		called = true
-		if oldState != StateIdle || newState != StateRecording {
-			t.Errorf("Expected Idle->Recording, got %s->%s", oldState, newState)
-		}
+	changes := make(chan [2]State, 1)
+	sm.Subscribe(func(change Change) {
+		changes <- [2]State{change.From, change.To}
 	})
 
 	if err := sm.Transition(StateRecording); err != nil {
 		t.Fatalf("Failed to transition to Recording: %v", err)
 	}
 
-	if !called {
-		t.Error("Callback was not called")
+	if change := receive(t, changes); change != [2]State{StateIdle, StateRecording} {
+		t.Errorf("Expected Idle->Recording, got %s->%s", change[0], change[1])
+	}
+}
+
+// TestSubscribeOrder tests that a subscriber receives changes in transition order
+func TestSubscribeOrder(t *testing.T) {
+	sm := NewStateMachine()
+
+	changes := make(chan [2]State, 64)
+	sm.Subscribe(func(change Change) {
+		changes <- [2]State{change.From, change.To}
+	})
+
+	for i := 0; i < 10; i++ {
+		sm.Transition(StateRecording)
+		sm.Transition(StateTranscribing)
+		sm.Transition(StateIdle)
+	}
+
+	expected := StateIdle
+	for i := 0; i < 30; i++ {
+		change := receive(t, changes)
+		if change[0] != expected {
+			t.Fatalf("Change %d out of order: %s->%s after %s", i, change[0], change[1], expected)
+		}
+		expected = change[1]
+	}
+}
+
+// TestSession tests that a session starts with the recording, is carried by
+// its changes and ends with the return to Idle
+func TestSession(t *testing.T) {
+	sm := NewStateMachine()
+
+	changes := make(chan Change, 8)
+	sm.Subscribe(func(change Change) {
+		changes <- change
+	})
+
+	if sm.Session() != nil {
+		t.Fatal("Expected no session in Idle")
+	}
+	sm.Fire(EventToggle)
+	current := sm.Session()
+	if current == nil {
+		t.Fatal("Expected a session while recording")
+	}
+	sm.Fire(EventToggle)
+	sm.Fire(EventFinish)
+	if sm.Session() != nil {
+		t.Error("Expected the session to end in Idle")
+	}
+
+	for i := 0; i < 3; i++ {
+		change := <-changes
+		if change.Session != current || change.Seq != uint64(i+1) {
+			t.Errorf("Change %d: expected session %s, got %+v", i, current.ID, change)
+		}
+	}
+
+	// The next recording is a new session, recorded in the history
+	sm.Fire(EventToggle)
+	if next := (<-changes).Session; next == nil || next == current {
+		t.Error("Expected a new session for the next recording")
+	}
+	if entries := sm.History().Entries; entries[len(entries)-1].Session != sm.Session().ID {
+		t.Errorf("Expected the session in the history, got %+v", entries[len(entries)-1])
+	}
+}
+
+// TestFireFor tests that a late event of an ended session does not move the next session
+func TestFireFor(t *testing.T) {
+	clock := newFakeClock()
+	table := DefaultTable()
+	table.Timeouts = map[State]time.Duration{StateTranscribing: time.Minute}
+	sm := NewStateMachineFromTable(table, clock)
+
+	// The first transcription times out, and a new recording starts from Error
+	sm.Fire(EventToggle)
+	first := sm.Session()
+	sm.Fire(EventToggle)
+	clock.Advance(time.Minute)
+	sm.Fire(EventToggle)
+	sm.Fire(EventToggle)
+	if sm.GetState() != StateTranscribing || sm.Session() == first {
+		t.Fatalf("Expected a second session transcribing, got %s", sm.GetState())
+	}
+
+	// The first transcription finishes late
+	if err := sm.FireFor(first, EventFinish); !errors.Is(err, ErrNoTransition) {
+		t.Errorf("Expected ErrNoTransition, got %v", err)
+	}
+	if sm.GetState() != StateTranscribing {
+		t.Errorf("Expected the second session to keep transcribing, got %s", sm.GetState())
+	}
+
+	if err := sm.FireFor(sm.Session(), EventFinish); err != nil || sm.GetState() != StateIdle {
+		t.Errorf("Expected the current session to finish, got %s, %v", sm.GetState(), err)
+	}
+}
+
+// TestSlowSubscriber tests that a blocked subscriber delays neither transitions nor other subscribers
+func TestSlowSubscriber(t *testing.T) {
+	sm := NewStateMachine()
+
+	release := make(chan struct{})
+	defer close(release)
+	sm.Subscribe(func(change Change) {
+		<-release
+	})
+	sm.Subscribe(func(change Change) {
+		panic("broken subscriber")
+	})
+
+	changes := make(chan [2]State, 2)
+	sm.Subscribe(func(change Change) {
+		changes <- [2]State{change.From, change.To}
+	})
+
+	done := make(chan struct{})
+	go func() {
+		sm.Transition(StateRecording)
+		sm.Transition(StateIdle)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Transition blocked by a slow subscriber")
+	}
+	receive(t, changes)
+	if change := receive(t, changes); change != [2]State{StateRecording, StateIdle} {
+		t.Errorf("Expected Recording->Idle, got %s->%s", change[0], change[1])
+	}
+}
+
+// TestUnsubscribe tests that no changes are delivered after Unsubscribe
+func TestUnsubscribe(t *testing.T) {
+	sm := NewStateMachine()
+
+	changes := make(chan [2]State, 2)
+	subscription := sm.Subscribe(func(change Change) {
+		changes <- [2]State{change.From, change.To}
+	})
+
+	sm.Transition(StateRecording)
+	receive(t, changes)
+
+	subscription.Unsubscribe()
+	subscription.Unsubscribe() // repeated calls are harmless
+	sm.Transition(StateIdle)
+
+	select {
+	case change := <-changes:
+		t.Errorf("Unexpected change after Unsubscribe: %s->%s", change[0], change[1])
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// fakeClock is a Clock that only moves on Advance
+type fakeClock struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// fakeTimer is a call scheduled on a fakeClock
+type fakeTimer struct {
+	at      time.Time
+	f       func()
+	stopped bool
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	timer := &fakeTimer{at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, timer)
+	return timer
+}
+
+// Advance moves the time forward and calls the timers that became due, in order
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	end := c.now.Add(d)
+	for {
+		var next *fakeTimer
+		for _, timer := range c.timers {
+			if !timer.stopped && !timer.at.After(end) && (next == nil || timer.at.Before(next.at)) {
+				next = timer
+			}
+		}
+		if next == nil {
+			break
+		}
+		next.stopped = true
+		c.now = next.at
+		// The call may create timers, so it runs without the lock
+		c.mutex.Unlock()
+		next.f()
+		c.mutex.Lock()
+	}
+	c.now = end
+	c.mutex.Unlock()
+}
+
+func (t *fakeTimer) Stop() bool {
+	stopped := t.stopped
+	t.stopped = true
+	return !stopped
+}
+
+// TestTimeouts tests the automatic transitions of the default table
+func TestTimeouts(t *testing.T) {
+	clock := newFakeClock()
+	table := DefaultTable()
+	table.Timeouts = map[State]time.Duration{
+		StateIdle:         time.Minute, // ignored: Idle has no rule for EventTimeout
+		StateRecording:    5 * time.Minute,
+		StateTranscribing: time.Minute,
+		StateError:        3 * time.Second,
+	}
+	sm := NewStateMachineFromTable(table, clock)
+
+	// Idle never times out, so no timer is armed
+	if len(clock.timers) != 0 {
+		t.Errorf("Expected no timer in Idle, got %d", len(clock.timers))
+	}
+	clock.Advance(time.Hour)
+	if sm.GetState() != StateIdle {
+		t.Fatalf("Expected Idle, got %s", sm.GetState())
+	}
+	if entries := sm.History().Entries; len(entries) != 0 {
+		t.Errorf("Expected no rejected timeout, got %+v", entries)
+	}
+
+	// A recording is stopped and transcribed at the maximum duration
+	sm.Fire(EventToggle)
+	clock.Advance(5*time.Minute - time.Second)
+	if sm.GetState() != StateRecording {
+		t.Fatalf("Expected Recording before the timeout, got %s", sm.GetState())
+	}
+	clock.Advance(time.Second)
+	if sm.GetState() != StateTranscribing {
+		t.Fatalf("Expected Transcribing after the maximum recording duration, got %s", sm.GetState())
+	}
+
+	// A transcription that takes too long ends in Error, shown until its timeout
+	clock.Advance(time.Minute)
+	if sm.GetState() != StateError {
+		t.Fatalf("Expected Error after the maximum transcribing time, got %s", sm.GetState())
+	}
+	clock.Advance(3 * time.Second)
+	if sm.GetState() != StateIdle {
+		t.Fatalf("Expected Idle after the error display time, got %s", sm.GetState())
+	}
+
+	// Leaving a state stops its timeout: the second recording gets the full duration
+	sm.Fire(EventToggle)
+	clock.Advance(4 * time.Minute)
+	sm.Fire(EventCancel)
+	sm.Fire(EventToggle)
+	clock.Advance(4 * time.Minute)
+	if sm.GetState() != StateRecording {
+		t.Errorf("Expected the previous recording's timeout to be stopped, got %s", sm.GetState())
+	}
+
+	entries := sm.History().Entries
+	if last := entries[len(entries)-1]; last.Rejected || last.Event != EventToggle {
+		t.Errorf("Unexpected last transition: %+v", last)
+	}
+}
+
+// TestHistory tests that transitions and rejected attempts are recorded with durations
+func TestHistory(t *testing.T) {
+	clock := newFakeClock()
+	sm := NewStateMachineFromTable(DefaultTable(), clock)
+
+	clock.Advance(time.Second)
+	sm.Transition(StateRecording)
+	clock.Advance(3 * time.Second)
+	sm.Transition(StateRecording) // rejected
+	clock.Advance(2 * time.Second)
+	sm.Transition(StateTranscribing)
+	clock.Advance(time.Second)
+
+	history := sm.History()
+	if len(history.Entries) != 3 || history.Current != StateTranscribing {
+		t.Fatalf("Unexpected history: %+v", history)
+	}
+	if !history.Entries[1].Rejected || history.Entries[1].Duration != 0 {
+		t.Errorf("Expected a rejected attempt, got %+v", history.Entries[1])
+	}
+
+	last := history.Entries[2]
+	if last.Rejected || last.From != StateRecording || last.To != StateTranscribing || last.Duration != 5*time.Second {
+		t.Errorf("Expected Recording -> Transcribing after 5s, got %+v", last)
+	}
+
+	expected := map[State]time.Duration{StateIdle: time.Second, StateRecording: 5 * time.Second, StateTranscribing: time.Second}
+	for state, duration := range expected {
+		if history.TimeInState[state] != duration {
+			t.Errorf("Expected %v in %s, got %v", duration, state, history.TimeInState[state])
+		}
+	}
+}
+
+// TestHistoryBounded tests that only the most recent entries are kept
+func TestHistoryBounded(t *testing.T) {
+	sm := NewStateMachine()
+
+	for i := 0; i < historySize; i++ {
+		sm.Transition(StateRecording)
+		sm.Transition(StateIdle)
+	}
+
+	history := sm.History()
+	if len(history.Entries) != historySize {
+		t.Fatalf("Expected %d entries, got %d", historySize, len(history.Entries))
+	}
+	if first := history.Entries[0]; first.From != StateIdle || first.To != StateRecording {
+		t.Errorf("Expected the oldest kept entry to be Idle -> Recording, got %+v", first)
 	}
 }
@@ -1,5 +1,14 @@
 package state
 
+import (
+	"errors"
+
+	"github.com/d-mozulyov/vox/internal/session"
+)
+
+// ErrNoTransition is returned by Fire when no rule applies to the event in the current state
+var ErrNoTransition = errors.New("no transition")
+
 // State represents the current state of the application
 type State int
 
@@ -8,6 +17,11 @@ const (
 	StateIdle State = iota
 	// StateRecording represents the recording state - voice recording is in progress
 	StateRecording
+	// StateTranscribing represents the transcribing state - the recording is being converted to text
+	StateTranscribing
+	// StateError represents the error state - the transcription failed or timed out,
+	// shown for a while before returning to idle
+	StateError
 )
 
 // String returns the string representation of the state
@@ -17,6 +31,10 @@ func (s State) String() string {
 		return "Idle"
 	case StateRecording:
 		return "Recording"
+	case StateTranscribing:
+		return "Transcribing"
+	case StateError:
+		return "Error"
 	default:
 		return "Unknown"
 	}
@@ -31,7 +49,28 @@ type StateMachine interface {
 	// Returns error if the transition is invalid
 	Transition(newState State) error
 
+	// Fire moves the machine by the rule of its Table for the event in the current state,
+	// so callers report what happened instead of computing the next state.
+	// The machine fires EventTimeout itself, see Table.Timeouts.
+	// Returns an error wrapping ErrNoTransition if no rule applies
+	Fire(event Event) error
+
+	// FireFor fires an event on behalf of a session: only while it is the current session,
+	// so a late event of a session that has ended cannot move the next one.
+	// Returns an error wrapping ErrNoTransition if the session is not current or no rule applies
+	FireFor(sess *session.Session, event Event) error
+
 	// Subscribe registers a callback for state changes
-	// The callback receives the old state and the new state
-	Subscribe(callback func(oldState, newState State))
+	// The callback receives the transition and its session. Callbacks are
+	// called asynchronously, each on its own goroutine, in transition order;
+	// a slow or panicking callback does not delay transitions or other callbacks.
+	// The returned Subscription stops delivery.
+	Subscribe(callback func(change Change)) Subscription
+
+	// Session returns the current session, nil in the Idle state
+	Session() *session.Session
+
+	// History returns a snapshot of the recent transitions, rejected attempts
+	// and the time spent in each state
+	History() History
 }
@@ -0,0 +1,127 @@
+package state
+
+import (
+	"sync"
+	"time"
+
+	"github.com/d-mozulyov/vox/internal/platform"
+	"github.com/d-mozulyov/vox/internal/session"
+)
+
+// Subscription is the handle of a registered callback, returned by Subscribe
+type Subscription interface {
+	// Unsubscribe stops delivery to the callback
+	// Changes still queued are dropped; a callback already running finishes
+	Unsubscribe()
+}
+
+// Change is an accepted transition, delivered to subscribers
+type Change struct {
+	Seq   uint64 // numbers the transitions of a machine, starting from 1
+	Time  time.Time
+	From  State
+	To    State
+	Event Event // the event fired, empty for a direct Transition
+	// Session is the recording the transition belongs to: the one started by
+	// this transition, or the one it ends when returning to Idle.
+	// Nil for transitions outside a session.
+	Session *session.Session
+}
+
+// subscriber delivers state changes to one callback on its own goroutine
+// Changes are queued in transition order, so a slow or panicking callback
+// only delays its own notifications, never the transition or other subscribers
+type subscriber struct {
+	machine  *stateMachine
+	callback func(change Change)
+
+	mutex   sync.Mutex
+	queue   []Change
+	lastSeq uint64        // last delivered change
+	wake    chan struct{} // signals queued changes, capacity 1
+	done    chan struct{} // closed by Unsubscribe
+	once    sync.Once
+}
+
+// newSubscriber creates a subscriber and starts its delivery goroutine
+func newSubscriber(machine *stateMachine, callback func(change Change)) *subscriber {
+	s := &subscriber{
+		machine:  machine,
+		callback: callback,
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// enqueue queues a change for delivery without blocking
+// The machine calls it with its lock held, so changes are queued in seq order
+func (s *subscriber) enqueue(change Change) {
+	s.mutex.Lock()
+	s.queue = append(s.queue, change)
+	s.mutex.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run delivers queued changes until Unsubscribe
+func (s *subscriber) run() {
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.wake:
+		}
+
+		for {
+			s.mutex.Lock()
+			if len(s.queue) == 0 {
+				s.mutex.Unlock()
+				break
+			}
+			change := s.queue[0]
+			s.queue = s.queue[1:]
+			s.mutex.Unlock()
+
+			select {
+			case <-s.done:
+				return
+			default:
+			}
+
+			s.deliver(change)
+		}
+	}
+}
+
+// deliver calls the callback, recovering from a panic so later changes are still delivered
+func (s *subscriber) deliver(change Change) {
+	logger := platform.GetLogger()
+
+	if change.Seq <= s.lastSeq {
+		logger.Error("State change #%d delivered out of order after #%d, skipped", change.Seq, s.lastSeq)
+		return
+	}
+	s.lastSeq = change.Seq
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("State subscriber panicked on change #%d (%s -> %s): %v",
+				change.Seq, change.From, change.To, r)
+		}
+	}()
+
+	s.callback(change)
+}
+
+// Unsubscribe removes the subscriber from the machine and stops its goroutine
+func (s *subscriber) Unsubscribe() {
+	s.once.Do(func() {
+		s.machine.removeSubscriber(s)
+		close(s.done)
+	})
+}
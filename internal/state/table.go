@@ -0,0 +1,100 @@
+package state
+
+import "time"
+
+// Event is something that happens to the application, such as a hotkey press
+// The machine's Table decides which state, if any, an event leads to
+type Event string
+
+const (
+	// EventToggle is the Start/Stop hotkey or menu item: starts recording, or stops it and transcribes
+	EventToggle Event = "Toggle"
+	// EventCancel is the Cancel hotkey or menu item: discards the recording
+	EventCancel Event = "Cancel"
+	// EventFinish ends the transcription: it succeeded, was cancelled or its recording was queued
+	EventFinish Event = "Finish"
+	// EventFail ends the transcription with an error
+	EventFail Event = "Fail"
+	// EventTimeout is fired by the machine when the current state's timeout expires
+	EventTimeout Event = "Timeout"
+)
+
+// Guard decides whether a rule applies
+// Called with the machine locked, so it must not call the state machine
+type Guard func() bool
+
+// Hook is called when a state is entered or left, before subscribers are notified
+// Called with the machine locked, so it must be quick and must not call the state machine
+type Hook func(from, to State)
+
+// Rule allows the transition From -> To, on Event or by Transition(To)
+type Rule struct {
+	From  State
+	Event Event
+	To    State
+	Guard Guard // optional, the rule applies only while it returns true
+	// Start makes the transition start a new session, which lasts until
+	// the machine returns to StateIdle
+	Start bool
+}
+
+// Table declares the transitions of a state machine and the hooks of its states
+type Table struct {
+	Rules   []Rule
+	OnExit  map[State]Hook // called before the state is left
+	OnEnter map[State]Hook // called after the state is entered
+	// Timeouts fire EventTimeout when a state lasts longer than its duration
+	// States without a rule for EventTimeout, or with a zero duration, never time out
+	Timeouts map[State]time.Duration
+}
+
+// DefaultTable returns the transitions of the application:
+// - Idle -> Recording on Toggle (start recording via hotkey, starts a session)
+// - Recording -> Transcribing on Toggle (stop recording via hotkey, transcription starts)
+// - Recording -> Transcribing on Timeout (maximum recording duration reached)
+// - Recording -> Idle on Cancel (recording cancelled and discarded)
+// - Transcribing -> Idle on Finish (transcription finished, cancelled or queued)
+// - Transcribing -> Error on Fail or Timeout (transcription failed or took too long)
+// - Error -> Idle on Timeout or Cancel (error shown long enough, or dismissed)
+// - Error -> Recording on Toggle (start a new recording and session right away)
+// Timeouts are not set, see Table.Timeouts
+func DefaultTable() Table {
+	return Table{
+		Rules: []Rule{
+			{From: StateIdle, Event: EventToggle, To: StateRecording, Start: true},
+			{From: StateRecording, Event: EventToggle, To: StateTranscribing},
+			{From: StateRecording, Event: EventTimeout, To: StateTranscribing},
+			{From: StateRecording, Event: EventCancel, To: StateIdle},
+			{From: StateTranscribing, Event: EventFinish, To: StateIdle},
+			{From: StateTranscribing, Event: EventFail, To: StateError},
+			{From: StateTranscribing, Event: EventTimeout, To: StateError},
+			{From: StateError, Event: EventTimeout, To: StateIdle},
+			{From: StateError, Event: EventCancel, To: StateIdle},
+			{From: StateError, Event: EventToggle, To: StateRecording, Start: true},
+		},
+	}
+}
+
+// handles reports whether a state has a rule for an event, whatever its guard
+func (t Table) handles(from State, event Event) bool {
+	for _, rule := range t.Rules {
+		if rule.From == from && rule.Event == event {
+			return true
+		}
+	}
+	return false
+}
+
+// find returns the first rule from a state that matches and whose guard passes
+func (t Table) find(from State, match func(rule Rule) bool) (Rule, bool) {
+	for _, rule := range t.Rules {
+		if rule.From != from || !match(rule) {
+			continue
+		}
+		if rule.Guard != nil && !rule.Guard() {
+			continue
+		}
+		return rule, true
+	}
+	return Rule{}, false
+}
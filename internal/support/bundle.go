@@ -0,0 +1,78 @@
+// Package support writes support bundles: a text file with what is needed to
+// diagnose a problem (version, platform, state history and recent log).
+// Recordings are never included; the log may contain transcripts, so users
+// should review a bundle before sharing it.
+package support
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/d-mozulyov/vox/internal/state"
+)
+
+// logLines is the number of log lines included in a bundle
+const logLines = 200
+
+// Info holds the contents of a support bundle
+type Info struct {
+	Version string
+	History state.History
+	LogPath string
+}
+
+// WriteBundle writes a support bundle to dir and returns its path
+func WriteBundle(dir string, info Info, now time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create support directory: %w", err)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Vox support bundle\n")
+	fmt.Fprintf(&b, "Created: %s\n", now.Format(time.RFC3339))
+	fmt.Fprintf(&b, "Version: %s\n", info.Version)
+	fmt.Fprintf(&b, "Platform: %s/%s\n", runtime.GOOS, runtime.GOARCH)
+
+	fmt.Fprintf(&b, "\n== State history ==\n%s", info.History)
+
+	fmt.Fprintf(&b, "\n== Log (last %d lines) ==\n", logLines)
+	lines, err := tail(info.LogPath, logLines)
+	if err != nil {
+		fmt.Fprintf(&b, "Log not available: %v\n", err)
+	}
+	for _, line := range lines {
+		b.WriteString(line + "\n")
+	}
+
+	path := filepath.Join(dir, "support-"+now.Format("20060102-150405")+".txt")
+	if err := os.WriteFile(path, []byte(b.String()), 0600); err != nil {
+		return "", fmt.Errorf("failed to write support bundle: %w", err)
+	}
+
+	return path, nil
+}
+
+// tail returns the last n lines of a file
+func tail(path string, n int) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if len(lines) > n {
+			lines = lines[1:]
+		}
+	}
+	return lines, scanner.Err()
+}
@@ -0,0 +1,56 @@
+package support
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/d-mozulyov/vox/internal/state"
+)
+
+// TestWriteBundle tests that a bundle holds the history and the end of the log
+func TestWriteBundle(t *testing.T) {
+	dir := t.TempDir()
+
+	logPath := filepath.Join(dir, "vox.log")
+	var log strings.Builder
+	for i := 1; i <= logLines+50; i++ {
+		fmt.Fprintf(&log, "line %d\n", i)
+	}
+	if err := os.WriteFile(logPath, []byte(log.String()), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	sm := state.NewStateMachine()
+	sm.Transition(state.StateRecording)
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	path, err := WriteBundle(filepath.Join(dir, "support"), Info{
+		Version: "1.2.3",
+		History: sm.History(),
+		LogPath: logPath,
+	}, now)
+	if err != nil {
+		t.Fatalf("WriteBundle failed: %v", err)
+	}
+	if filepath.Base(path) != "support-20260102-030405.txt" {
+		t.Errorf("Unexpected bundle name: %s", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle := string(data)
+	for _, expected := range []string{"Version: 1.2.3", "Current state: Recording", "Idle -> Recording after", "line 51\n", "line 250\n"} {
+		if !strings.Contains(bundle, expected) {
+			t.Errorf("Bundle does not contain %q:\n%s", expected, bundle)
+		}
+	}
+	if strings.Contains(bundle, "line 50\n") {
+		t.Error("Bundle contains more than the last log lines")
+	}
+}
@@ -0,0 +1,65 @@
+package transcription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/d-mozulyov/vox/internal/platform"
+)
+
+// chainLink is a provider's transcriber in a failover chain
+type chainLink struct {
+	Transcriber
+	name  string
+	local bool
+}
+
+// chain implements Transcriber by trying providers in order
+// The same audio goes to the next provider only after a temporary failure
+// (timeout, 429, 5xx, network error). Authentication and other errors are
+// returned right away, so they reach the user instead of being hidden by a fallback.
+type chain struct {
+	links []chainLink
+}
+
+// newChain creates a failover chain of transcribers
+func newChain(links []chainLink) Transcriber {
+	return &chain{links: links}
+}
+
+// Transcribe tries the providers in order until one succeeds
+func (c *chain) Transcribe(ctx context.Context, req Request, onDelta func(text string) error) (Result, error) {
+	logger := platform.GetLogger()
+
+	var lastErr error
+	for i, link := range c.links {
+		if i > 0 {
+			logger.Warn("Transcription by %s failed: %v. Falling back to %s", c.links[i-1].name, lastErr, link.name)
+		}
+
+		// Text passed to onDelta cannot be taken back, so only failures
+		// before anything was delivered may fall back
+		delivered := false
+		result, err := link.Transcribe(ctx, req, func(text string) error {
+			delivered = true
+			if onDelta != nil {
+				return onDelta(text)
+			}
+			return nil
+		})
+		if err == nil {
+			if i > 0 {
+				logger.Info("Transcribed by fallback provider %s", link.name)
+			}
+			return result, nil
+		}
+
+		if delivered || !errors.Is(err, ErrTemporary) {
+			return Result{}, fmt.Errorf("%s: %w", link.name, err)
+		}
+		lastErr = err
+	}
+
+	return Result{}, fmt.Errorf("all providers failed, last error: %w", lastErr)
+}
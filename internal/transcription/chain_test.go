@@ -0,0 +1,141 @@
+package transcription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/d-mozulyov/vox/pkg/config"
+)
+
+// newStatusServer creates a server that answers with the given status,
+// or with a transcript for 200, and counts requests
+func newStatusServer(t *testing.T, status int, requests *int) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		if status != http.StatusOK {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		fmt.Fprint(w, `{"text":"Hello."}`)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// chainConfig creates a configuration with whisper providers for the given servers
+func chainConfig(servers ...*httptest.Server) config.TranscriptionConfig {
+	var cfg config.TranscriptionConfig
+	for i, server := range servers {
+		// Retries are tested separately, here every failure goes to the next provider
+		provider := config.ProviderConfig{
+			Backend: config.BackendWhisper,
+			BaseURL: server.URL,
+			Retry:   config.RetryConfig{MaxAttempts: 1},
+		}
+		if i == 0 {
+			cfg.Provider = provider
+		} else {
+			cfg.Fallbacks = append(cfg.Fallbacks, provider)
+		}
+	}
+	return cfg
+}
+
+// TestChainFailover tests that temporary failures fall back to the next provider
+func TestChainFailover(t *testing.T) {
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusServiceUnavailable} {
+		var first, second int
+		cfg := chainConfig(newStatusServer(t, status, &first), newStatusServer(t, http.StatusOK, &second))
+
+		transcriber, err := New(cfg)
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		result, err := transcriber.Transcribe(context.Background(), testRequest, nil)
+		if err != nil {
+			t.Fatalf("Status %d: Transcribe failed: %v", status, err)
+		}
+		if result.Backend != cfg.Fallbacks[0].BaseURL {
+			t.Errorf("Status %d: expected fallback backend, got %q", status, result.Backend)
+		}
+		if first != 1 || second != 1 {
+			t.Errorf("Status %d: expected one request per provider, got %d and %d", status, first, second)
+		}
+	}
+}
+
+// TestChainNetworkError tests that an unreachable provider falls back
+func TestChainNetworkError(t *testing.T) {
+	var first, second int
+	down := newStatusServer(t, http.StatusOK, &first)
+	down.Close()
+
+	transcriber, err := New(chainConfig(down, newStatusServer(t, http.StatusOK, &second)))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, err := transcriber.Transcribe(context.Background(), testRequest, nil); err != nil {
+		t.Fatalf("Transcribe failed: %v", err)
+	}
+	if second != 1 {
+		t.Errorf("Expected fallback request, got %d", second)
+	}
+}
+
+// TestChainNoFailover tests that authentication and client errors are returned right away
+func TestChainNoFailover(t *testing.T) {
+	for _, status := range []int{http.StatusUnauthorized, http.StatusBadRequest} {
+		var first, second int
+		transcriber, err := New(chainConfig(newStatusServer(t, status, &first), newStatusServer(t, http.StatusOK, &second)))
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+
+		_, err = transcriber.Transcribe(context.Background(), testRequest, nil)
+		if err == nil {
+			t.Fatalf("Status %d: expected error", status)
+		}
+		if status == http.StatusUnauthorized && !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("Expected ErrUnauthorized, got %v", err)
+		}
+		if second != 0 {
+			t.Errorf("Status %d: expected no fallback request, got %d", status, second)
+		}
+	}
+}
+
+// TestChainLocalProvider tests that a local provider never falls back to a cloud provider
+func TestChainLocalProvider(t *testing.T) {
+	var local, cloud, otherLocal int
+	cfg := chainConfig(newStatusServer(t, http.StatusServiceUnavailable, &local), newStatusServer(t, http.StatusOK, &cloud))
+	cfg.Provider.Local = true
+
+	transcriber, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, err := transcriber.Transcribe(context.Background(), testRequest, nil); !errors.Is(err, ErrTemporary) {
+		t.Errorf("Expected the local provider's error, got %v", err)
+	}
+	if cloud != 0 {
+		t.Errorf("Audio was sent to a cloud provider")
+	}
+
+	// Another local provider is a valid fallback
+	fallback := newStatusServer(t, http.StatusOK, &otherLocal)
+	cfg.Fallbacks = append(cfg.Fallbacks, config.ProviderConfig{Backend: config.BackendWhisper, BaseURL: fallback.URL, Local: true, Retry: cfg.Provider.Retry})
+	transcriber, err = New(cfg)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, err := transcriber.Transcribe(context.Background(), testRequest, nil); err != nil {
+		t.Errorf("Transcribe failed: %v", err)
+	}
+	if cloud != 0 || otherLocal != 1 {
+		t.Errorf("Expected only the local fallback to be used, got cloud %d, local %d", cloud, otherLocal)
+	}
+}
@@ -0,0 +1,275 @@
+package transcription
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/d-mozulyov/vox/internal/platform"
+	"github.com/d-mozulyov/vox/internal/wav"
+	"github.com/d-mozulyov/vox/pkg/config"
+)
+
+// chatClient implements Transcriber using an OpenAI-compatible /chat/completions endpoint
+// The recording is sent as a base64 WAV "input_audio" content part
+type chatClient struct {
+	name       string
+	baseURL    string
+	apiKey     string
+	model      string
+	prompt     string
+	stream     bool
+	httpClient *http.Client
+}
+
+// chatRequest is the /chat/completions request body
+type chatRequest struct {
+	Model         string         `json:"model"`
+	Messages      []chatMessage  `json:"messages"`
+	Stream        bool           `json:"stream,omitempty"`
+	StreamOptions *streamOptions `json:"stream_options,omitempty"`
+}
+
+// streamOptions asks for the usage of a streamed response
+// OpenAI-compatible servers leave it out of streams unless IncludeUsage is set
+type streamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// chatMessage is a single message; Content is a string or a list of parts
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content any    `json:"content"`
+}
+
+// contentPart is a part of a multi-part message content
+type contentPart struct {
+	Type       string `json:"type"`
+	Text       string `json:"text,omitempty"`
+	InputAudio string `json:"input_audio,omitempty"`
+}
+
+// chatResponse is the /chat/completions response body and stream chunk
+// Full responses fill Message, stream chunks fill Delta
+// Usage comes with the full response, or with the last chunk of a stream
+type chatResponse struct {
+	Choices []struct {
+		Message      chatContent `json:"message"`
+		Delta        chatContent `json:"delta"`
+		FinishReason string      `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *Usage    `json:"usage"`
+	Error *apiError `json:"error"`
+}
+
+// chatContent is the text of a response message or delta
+type chatContent struct {
+	Content string `json:"content"`
+}
+
+// apiError is an error reported by the API inside a response body
+type apiError struct {
+	Message string `json:"message"`
+}
+
+// newChatClient creates a transcriber for an OpenAI-compatible /chat/completions endpoint
+func newChatClient(provider config.ProviderConfig, cfg config.TranscriptionConfig) Transcriber {
+	return &chatClient{
+		name:       providerName(provider),
+		baseURL:    strings.TrimSuffix(provider.BaseURL, "/"),
+		apiKey:     provider.APIKey,
+		model:      provider.Model,
+		prompt:     chatPrompt(cfg),
+		stream:     cfg.Stream,
+		httpClient: &http.Client{},
+	}
+}
+
+// chatPrompt builds the system prompt with the language and glossary hints
+func chatPrompt(cfg config.TranscriptionConfig) string {
+	var hints []string
+	if cfg.Prompt != "" {
+		hints = append(hints, cfg.Prompt)
+	}
+	if cfg.Language != "" {
+		hints = append(hints, "Language: "+cfg.Language+".")
+	}
+	if len(cfg.Glossary) > 0 {
+		hints = append(hints, "Spell these terms exactly: "+strings.Join(cfg.Glossary, ", ")+".")
+	}
+	return strings.Join(hints, "\n")
+}
+
+// Transcribe sends the audio to the endpoint and returns the transcript
+func (c *chatClient) Transcribe(ctx context.Context, req Request, onDelta func(text string) error) (Result, error) {
+	logger := platform.GetLogger()
+
+	body, err := json.Marshal(c.newRequest(req))
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	if c.stream {
+		httpReq.Header.Set("Accept", "text/event-stream")
+	}
+
+	logger.Info("Sending %.1f s of audio to %s (model: %s, stream: %v)",
+		float64(len(req.Samples))/float64(req.SampleRate), c.baseURL, c.model, c.stream)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return Result{}, requestError(ctx, err)
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp); err != nil {
+		return Result{}, err
+	}
+
+	var text string
+	var usage Usage
+	if c.stream {
+		text, usage, err = c.readStream(ctx, resp.Body, onDelta)
+	} else {
+		text, usage, err = c.readResponse(resp.Body, onDelta)
+	}
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{Text: text, Backend: c.name, Model: c.model, Usage: usage}, nil
+}
+
+// newRequest builds the request body: the system prompt followed by the audio
+func (c *chatClient) newRequest(req Request) chatRequest {
+	audio := wav.Encode(req.Samples, req.SampleRate)
+
+	var messages []chatMessage
+	if c.prompt != "" {
+		messages = append(messages, chatMessage{Role: "system", Content: c.prompt})
+	}
+	messages = append(messages, chatMessage{
+		Role: "user",
+		Content: []contentPart{
+			{Type: "input_audio", InputAudio: base64.StdEncoding.EncodeToString(audio)},
+		},
+	})
+
+	request := chatRequest{
+		Model:    c.model,
+		Messages: messages,
+		Stream:   c.stream,
+	}
+	if c.stream {
+		request.StreamOptions = &streamOptions{IncludeUsage: true}
+	}
+	return request
+}
+
+// readResponse reads a complete (non-streamed) response
+func (c *chatClient) readResponse(body io.Reader, onDelta func(text string) error) (string, Usage, error) {
+	var resp chatResponse
+	if err := json.NewDecoder(body).Decode(&resp); err != nil {
+		return "", Usage{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if resp.Error != nil {
+		return "", Usage{}, fmt.Errorf("transcription failed: %s", resp.Error.Message)
+	}
+	if len(resp.Choices) == 0 {
+		return "", Usage{}, fmt.Errorf("response contains no choices")
+	}
+
+	text := strings.TrimSpace(resp.Choices[0].Message.Content)
+	if onDelta != nil && text != "" {
+		if err := onDelta(text); err != nil {
+			return "", Usage{}, err
+		}
+	}
+
+	var usage Usage
+	if resp.Usage != nil {
+		usage = *resp.Usage
+	}
+
+	return text, usage, nil
+}
+
+// readStream reads a streamed response and passes deltas to onDelta as they arrive
+// A stream that breaks after text was received returns a *StreamError; one that
+// breaks before is a temporary failure, so the request can be retried
+func (c *chatClient) readStream(ctx context.Context, body io.Reader, onDelta func(text string) error) (string, Usage, error) {
+	var text strings.Builder
+	var usage Usage
+	finished := false
+	var chunkErr error // a chunk that could not be decoded or reported an error, as opposed to a broken stream
+
+	err := readEvents(body, func(data string) error {
+		var chunk chatResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			chunkErr = fmt.Errorf("failed to decode stream chunk: %w", err)
+			return chunkErr
+		}
+		if chunk.Error != nil {
+			chunkErr = fmt.Errorf("transcription failed: %s", chunk.Error.Message)
+			return chunkErr
+		}
+		if chunk.Usage != nil {
+			usage = *chunk.Usage
+		}
+		if len(chunk.Choices) == 0 {
+			return nil
+		}
+
+		choice := chunk.Choices[0]
+		if choice.FinishReason != "" {
+			finished = true
+		}
+
+		// Leading whitespace of the transcript is dropped
+		delta := choice.Delta.Content
+		if text.Len() == 0 {
+			delta = strings.TrimLeft(delta, " \t\r\n")
+		}
+		if delta == "" {
+			return nil
+		}
+
+		text.WriteString(delta)
+		if onDelta != nil {
+			return onDelta(delta)
+		}
+		return nil
+	})
+
+	// Some servers close the stream after the finish reason without [DONE]
+	if errors.Is(err, io.ErrUnexpectedEOF) && finished {
+		err = nil
+	}
+	if err != nil {
+		switch {
+		case text.Len() > 0:
+			return "", Usage{}, &StreamError{Text: text.String(), Err: err}
+		case chunkErr != nil:
+			return "", Usage{}, err
+		case ctx.Err() != nil:
+			return "", Usage{}, fmt.Errorf("transcription request cancelled: %w", ctx.Err())
+		}
+		return "", Usage{}, fmt.Errorf("%w: failed to read response stream: %w", ErrTemporary, err)
+	}
+
+	return strings.TrimSpace(text.String()), usage, nil
+}
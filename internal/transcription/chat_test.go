@@ -0,0 +1,221 @@
+package transcription
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/d-mozulyov/vox/pkg/config"
+)
+
+// newTestClient creates a chat client for the given test server
+func newTestClient(server *httptest.Server, stream bool) Transcriber {
+	provider := config.ProviderConfig{
+		Backend: config.BackendChat,
+		BaseURL: server.URL + "/v1/",
+		APIKey:  "test-key",
+		Model:   "test-model",
+	}
+	return newChatClient(provider, config.TranscriptionConfig{
+		Prompt: "Transcribe",
+		Stream: stream,
+	})
+}
+
+// testRequest is a short silent recording
+var testRequest = Request{Samples: make([]int16, 1600), SampleRate: 16000}
+
+// writeEvents writes stream chunks with the given delta texts
+func writeEvents(w http.ResponseWriter, deltas ...string) {
+	for _, delta := range deltas {
+		data, _ := json.Marshal(map[string]any{
+			"choices": []any{map[string]any{"delta": map[string]string{"content": delta}}},
+		})
+		fmt.Fprintf(w, "data: %s\n\n", data)
+	}
+	w.(http.Flusher).Flush()
+}
+
+// TestChatClient tests a complete (non-streamed) response and the request format
+func TestChatClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer test-key" {
+			t.Errorf("Unexpected Authorization header: %q", auth)
+		}
+
+		var req struct {
+			Model    string `json:"model"`
+			Stream   bool   `json:"stream"`
+			Messages []struct {
+				Role    string          `json:"role"`
+				Content json.RawMessage `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Invalid request body: %v", err)
+		}
+		if req.Model != "test-model" || req.Stream || len(req.Messages) != 2 {
+			t.Fatalf("Unexpected request: %+v", req)
+		}
+
+		var parts []contentPart
+		if err := json.Unmarshal(req.Messages[1].Content, &parts); err != nil || len(parts) != 1 {
+			t.Fatalf("Unexpected user content: %s", req.Messages[1].Content)
+		}
+		wav, err := base64.StdEncoding.DecodeString(parts[0].InputAudio)
+		if err != nil || !strings.HasPrefix(string(wav), "RIFF") {
+			t.Errorf("Audio is not a base64 WAV file")
+		}
+
+		fmt.Fprint(w, `{"choices":[{"message":{"content":" Hello world. "},"finish_reason":"stop"}],`+
+			`"usage":{"prompt_tokens":120,"completion_tokens":4,"total_tokens":124}}`)
+	}))
+	defer server.Close()
+
+	var deltas []string
+	result, err := newTestClient(server, false).Transcribe(context.Background(), testRequest, func(text string) error {
+		deltas = append(deltas, text)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Transcribe failed: %v", err)
+	}
+	if result.Text != "Hello world." || result.Model != "test-model" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if result.Usage != (Usage{PromptTokens: 120, CompletionTokens: 4, TotalTokens: 124}) {
+		t.Errorf("Unexpected usage: %+v", result.Usage)
+	}
+	if len(deltas) != 1 || deltas[0] != "Hello world." {
+		t.Errorf("Expected the full text in one delta, got %q", deltas)
+	}
+}
+
+// TestChatClientStream tests that streamed deltas are passed on as they arrive
+func TestChatClientStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": keep-alive\n\n")
+		writeEvents(w, " Hel", "lo wor", "ld.")
+		// The last chunk carries the usage of the whole response
+		fmt.Fprint(w, `data: {"choices":[{"delta":{},"finish_reason":"stop"}],"usage":{"prompt_tokens":90,"completion_tokens":3,"total_tokens":93}}`+"\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	var deltas []string
+	result, err := newTestClient(server, true).Transcribe(context.Background(), testRequest, func(text string) error {
+		deltas = append(deltas, text)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Transcribe failed: %v", err)
+	}
+	if result.Text != "Hello world." {
+		t.Errorf("Expected %q, got %q", "Hello world.", result.Text)
+	}
+	if result.Usage.TotalTokens != 93 {
+		t.Errorf("Unexpected usage: %+v", result.Usage)
+	}
+	if strings.Join(deltas, "|") != "Hel|lo wor|ld." {
+		t.Errorf("Unexpected deltas: %q", deltas)
+	}
+}
+
+// TestChatClientStreamUsage tests that streams ask for usage and read it from the final chunk,
+// which OpenAI-compatible servers send after the finish reason with no choices
+func TestChatClientStreamUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			StreamOptions struct {
+				IncludeUsage bool `json:"include_usage"`
+			} `json:"stream_options"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || !req.StreamOptions.IncludeUsage {
+			t.Errorf("Expected stream_options.include_usage, got %+v, %v", req, err)
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		writeEvents(w, "Hello.")
+		fmt.Fprint(w, `data: {"choices":[{"delta":{},"finish_reason":"stop"}],"usage":null}`+"\n\n")
+		fmt.Fprint(w, `data: {"choices":[],"usage":{"prompt_tokens":90,"completion_tokens":3,"total_tokens":93}}`+"\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	result, err := newTestClient(server, true).Transcribe(context.Background(), testRequest, nil)
+	if err != nil {
+		t.Fatalf("Transcribe failed: %v", err)
+	}
+	if result.Usage != (Usage{PromptTokens: 90, CompletionTokens: 3, TotalTokens: 93}) {
+		t.Errorf("Unexpected usage: %+v", result.Usage)
+	}
+}
+
+// TestChatClientStreamDropped tests that a stream dropped before any text is a temporary failure
+func TestChatClientStreamDropped(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": keep-alive\n\n")
+		w.(http.Flusher).Flush()
+
+		// The connection is reset before the first delta
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("Hijack failed: %v", err)
+			return
+		}
+		conn.Close()
+	}))
+	defer server.Close()
+
+	_, err := newTestClient(server, true).Transcribe(context.Background(), testRequest, nil)
+	if !errors.Is(err, ErrTemporary) || !Retryable(err) {
+		t.Errorf("Expected a temporary error, got %v", err)
+	}
+	var streamErr *StreamError
+	if errors.As(err, &streamErr) {
+		t.Errorf("Expected no StreamError without text, got %v", err)
+	}
+}
+
+// TestChatClientStreamBroken tests that a broken stream reports the text received so far
+func TestChatClientStreamBroken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeEvents(w, "Hello ", "wor")
+		// The connection closes without [DONE]
+	}))
+	defer server.Close()
+
+	_, err := newTestClient(server, true).Transcribe(context.Background(), testRequest, nil)
+
+	var streamErr *StreamError
+	if !errors.As(err, &streamErr) {
+		t.Fatalf("Expected StreamError, got %v", err)
+	}
+	if streamErr.Text != "Hello wor" {
+		t.Errorf("Expected received text %q, got %q", "Hello wor", streamErr.Text)
+	}
+}
+
+// TestChatClientHTTPError tests that HTTP errors are returned with the status
+func TestChatClientHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"Unauthorized"}`, http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	_, err := newTestClient(server, true).Transcribe(context.Background(), testRequest, nil)
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Expected 401 error, got %v", err)
+	}
+}
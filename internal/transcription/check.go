@@ -0,0 +1,118 @@
+package transcription
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/d-mozulyov/vox/pkg/config"
+)
+
+// AudioSupport tells whether a provider accepts audio input
+type AudioSupport int
+
+const (
+	// AudioUnknown means the check failed before the provider judged the audio
+	AudioUnknown AudioSupport = iota
+	// AudioSupported means the provider transcribed the test clip
+	AudioSupported
+	// AudioUnsupported means the provider rejected the request with the audio (400, 415, 422)
+	AudioUnsupported
+)
+
+// String returns the string representation of the audio support
+func (a AudioSupport) String() string {
+	switch a {
+	case AudioSupported:
+		return "supported"
+	case AudioUnsupported:
+		return "not supported"
+	default:
+		return "unknown"
+	}
+}
+
+// CheckResult is the outcome of sending the test clip to one provider
+type CheckResult struct {
+	Provider   string
+	Backend    string
+	Model      string
+	Latency    time.Duration
+	StatusCode int    // HTTP status, 0 for the command backend or if no response was received
+	Reply      string // transcript of the test clip
+	Audio      AudioSupport
+	Err        error
+}
+
+// checkClipSeconds is the length of the test clip
+const checkClipSeconds = 1
+
+// Check sends a short synthesized clip to the configured provider and its fallbacks,
+// once each and without retries, so the result shows the state of every provider
+func Check(ctx context.Context, cfg config.TranscriptionConfig) []CheckResult {
+	providers := append([]config.ProviderConfig{cfg.Provider}, cfg.Fallbacks...)
+	results := make([]CheckResult, 0, len(providers))
+	for _, provider := range providers {
+		results = append(results, checkProvider(ctx, provider, cfg))
+	}
+	return results
+}
+
+// checkProvider sends the test clip to a single provider
+func checkProvider(ctx context.Context, provider config.ProviderConfig, cfg config.TranscriptionConfig) CheckResult {
+	provider, err := provider.Resolve()
+	result := CheckResult{
+		Provider: providerName(provider),
+		Backend:  provider.Backend,
+		Model:    provider.Model,
+	}
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	transcriber, err := newBackend(provider, cfg)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	startedAt := time.Now()
+	reply, err := transcriber.Transcribe(ctx, checkClip(), nil)
+	result.Latency = time.Since(startedAt)
+	result.Reply = reply.Text
+	result.Err = err
+
+	var statusErr *StatusError
+	switch {
+	case err == nil:
+		result.Audio = AudioSupported
+		if provider.Backend != config.BackendCommand {
+			result.StatusCode = http.StatusOK
+		}
+	case errors.As(err, &statusErr):
+		result.StatusCode = statusErr.StatusCode
+		switch statusErr.StatusCode {
+		case http.StatusBadRequest, http.StatusUnsupportedMediaType, http.StatusUnprocessableEntity:
+			result.Audio = AudioUnsupported
+		}
+	}
+
+	return result
+}
+
+// checkClip returns the test clip: a quiet 440 Hz tone with a short fade in and out
+// Models usually reply to it with an empty or a short transcript
+func checkClip() Request {
+	const sampleRate = 16000
+	const fade = sampleRate / 20
+
+	samples := make([]int16, checkClipSeconds*sampleRate)
+	for i := range samples {
+		gain := math.Min(1, float64(min(i, len(samples)-1-i))/fade)
+		samples[i] = int16(8000 * gain * math.Sin(2*math.Pi*440*float64(i)/sampleRate))
+	}
+	return Request{Samples: samples, SampleRate: sampleRate}
+}
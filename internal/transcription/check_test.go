@@ -0,0 +1,91 @@
+package transcription
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/d-mozulyov/vox/pkg/config"
+)
+
+// TestCheck tests the check of a working provider, a provider without audio input
+// and an unavailable fallback, each sent the clip exactly once
+func TestCheck(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		var req chatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Invalid request body: %v", err)
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		switch req.Model {
+		case "audio-model":
+			fmt.Fprint(w, `{"choices":[{"message":{"content":"beep"},"finish_reason":"stop"}]}`)
+		case "text-model":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, `{"error":{"message":"input_audio is not supported by this model"}}`)
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	provider := func(model string) config.ProviderConfig {
+		return config.ProviderConfig{Backend: config.BackendChat, BaseURL: server.URL, Model: model}
+	}
+	results := Check(context.Background(), config.TranscriptionConfig{
+		Provider:  provider("audio-model"),
+		Fallbacks: []config.ProviderConfig{provider("text-model"), provider("overloaded-model")},
+	})
+
+	if len(results) != 3 || calls.Load() != 3 {
+		t.Fatalf("Expected 3 results from 3 requests, got %d from %d", len(results), calls.Load())
+	}
+
+	ok := results[0]
+	if ok.Err != nil || ok.StatusCode != 200 || ok.Reply != "beep" || ok.Audio != AudioSupported || ok.Model != "audio-model" {
+		t.Errorf("Unexpected result of the working provider: %+v", ok)
+	}
+
+	unsupported := results[1]
+	if unsupported.Err == nil || unsupported.StatusCode != 422 || unsupported.Audio != AudioUnsupported {
+		t.Errorf("Unexpected result of the provider without audio input: %+v", unsupported)
+	}
+
+	unavailable := results[2]
+	if unavailable.Err == nil || unavailable.StatusCode != 503 || unavailable.Audio != AudioUnknown {
+		t.Errorf("Unexpected result of the unavailable provider: %+v", unavailable)
+	}
+}
+
+// TestCheckInvalidProvider tests that a provider that cannot be created is reported
+func TestCheckInvalidProvider(t *testing.T) {
+	results := Check(context.Background(), config.TranscriptionConfig{
+		Provider: config.ProviderConfig{Preset: "unknown"},
+	})
+	if len(results) != 1 || results[0].Err == nil || results[0].Latency != 0 {
+		t.Errorf("Expected a configuration error, got %+v", results)
+	}
+}
+
+// TestCheckClip tests that the test clip is a second of audible, unclipped audio
+func TestCheckClip(t *testing.T) {
+	clip := checkClip()
+	if len(clip.Samples) != clip.SampleRate {
+		t.Fatalf("Expected 1 s of audio, got %d samples at %d Hz", len(clip.Samples), clip.SampleRate)
+	}
+
+	var peak int16
+	for _, sample := range clip.Samples {
+		peak = max(peak, sample)
+	}
+	if peak < 4000 || clip.Samples[0] != 0 {
+		t.Errorf("Unexpected clip: peak %d, first sample %d", peak, clip.Samples[0])
+	}
+}
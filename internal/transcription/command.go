@@ -0,0 +1,218 @@
+package transcription
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/d-mozulyov/vox/internal/platform"
+	"github.com/d-mozulyov/vox/internal/wav"
+	"github.com/d-mozulyov/vox/pkg/config"
+)
+
+// Exit codes with a special meaning for the command backend (from sysexits.h)
+const (
+	// exitTempFail reports a temporary failure, e.g. the ASR service is busy
+	exitTempFail = 75
+	// exitNoPerm reports missing credentials or permissions
+	exitNoPerm = 77
+)
+
+// commandClient implements Transcriber by running a user-configured executable
+// The recording is written to its stdin and the transcript is read from its stdout
+type commandClient struct {
+	name        string
+	command     []string
+	audioFormat string
+	contextFile bool
+	context     commandContext
+}
+
+// commandContext is the transcription context passed to the command
+type commandContext struct {
+	Language    string   `json:"language,omitempty"`
+	Glossary    []string `json:"glossary,omitempty"`
+	Prompt      string   `json:"prompt,omitempty"`
+	SampleRate  int      `json:"sample_rate"`
+	AudioFormat string   `json:"audio_format"`
+}
+
+// newCommandClient creates a transcriber that runs an external command
+func newCommandClient(provider config.ProviderConfig, cfg config.TranscriptionConfig) Transcriber {
+	audioFormat := provider.AudioFormat
+	if audioFormat == "" {
+		audioFormat = "wav"
+	}
+
+	return &commandClient{
+		name:        providerName(provider),
+		command:     provider.Command,
+		audioFormat: audioFormat,
+		contextFile: provider.ContextMode == "file",
+		context: commandContext{
+			Language:    cfg.Language,
+			Glossary:    cfg.Glossary,
+			Prompt:      cfg.Prompt,
+			AudioFormat: audioFormat,
+		},
+	}
+}
+
+// Transcribe runs the command with the audio on stdin and returns its stdout
+// The transcript is passed to onDelta at once when the command has finished
+func (c *commandClient) Transcribe(ctx context.Context, req Request, onDelta func(text string) error) (Result, error) {
+	logger := platform.GetLogger()
+
+	audio, err := c.encodeAudio(req)
+	if err != nil {
+		return Result{}, err
+	}
+
+	cmd := exec.CommandContext(ctx, c.command[0], c.command[1:]...)
+	// Do not wait for children that keep the pipes open after the command is killed
+	cmd.WaitDelay = time.Second
+
+	env, cleanup, err := c.environment(req)
+	if err != nil {
+		return Result{}, err
+	}
+	defer cleanup()
+	cmd.Env = append(os.Environ(), env...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = bytes.NewReader(audio)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	logger.Info("Running %s with %.1f s of %s audio", c.name, float64(len(req.Samples))/float64(req.SampleRate), c.audioFormat)
+
+	runErr := cmd.Run()
+	lastLine := c.logStderr(&stderr)
+
+	if err := c.mapError(ctx, runErr, lastLine); err != nil {
+		return Result{}, err
+	}
+
+	text := strings.TrimSpace(stdout.String())
+	if onDelta != nil && text != "" {
+		if err := onDelta(text); err != nil {
+			return Result{}, err
+		}
+	}
+
+	return Result{Text: text, Backend: c.name}, nil
+}
+
+// encodeAudio encodes the recording in the configured format
+func (c *commandClient) encodeAudio(req Request) ([]byte, error) {
+	switch c.audioFormat {
+	case "wav":
+		return wav.Encode(req.Samples, req.SampleRate), nil
+	case "pcm":
+		var buf bytes.Buffer
+		binary.Write(&buf, binary.LittleEndian, req.Samples)
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unsupported audio format: %s", c.audioFormat)
+	}
+}
+
+// environment returns the variables that pass the context to the command:
+// VOX_* variables, or VOX_CONTEXT_FILE with the path of a temporary JSON file
+// The returned cleanup function removes the file
+func (c *commandClient) environment(req Request) ([]string, func(), error) {
+	values := c.context
+	values.SampleRate = req.SampleRate
+
+	if !c.contextFile {
+		return []string{
+			"VOX_SAMPLE_RATE=" + strconv.Itoa(values.SampleRate),
+			"VOX_AUDIO_FORMAT=" + values.AudioFormat,
+			"VOX_LANGUAGE=" + values.Language,
+			"VOX_GLOSSARY=" + strings.Join(values.Glossary, ","),
+			"VOX_PROMPT=" + values.Prompt,
+		}, func() {}, nil
+	}
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode command context: %w", err)
+	}
+
+	file, err := os.CreateTemp("", "vox-context-*.json")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create command context file: %w", err)
+	}
+	cleanup := func() { os.Remove(file.Name()) }
+
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to write command context file: %w", err)
+	}
+
+	return []string{"VOX_CONTEXT_FILE=" + file.Name()}, cleanup, nil
+}
+
+// logStderr writes the command's stderr to the log line by line
+// Returns the last non-empty line for error messages
+func (c *commandClient) logStderr(stderr *bytes.Buffer) string {
+	logger := platform.GetLogger()
+
+	var lastLine string
+	scanner := bufio.NewScanner(stderr)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		logger.Info("%s: %s", c.name, line)
+		lastLine = line
+	}
+
+	return lastLine
+}
+
+// mapError converts the result of running the command to a transcription error
+// Exit code 75 (EX_TEMPFAIL) is temporary, 77 (EX_NOPERM) is unauthorized
+// A command killed because ctx is done (timeout, cancellation) returns the ctx error
+func (c *commandClient) mapError(ctx context.Context, runErr error, lastLine string) error {
+	if runErr == nil {
+		return nil
+	}
+
+	if ctx.Err() != nil {
+		return fmt.Errorf("%s stopped: %w", c.name, ctx.Err())
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(runErr, &exitErr) {
+		return fmt.Errorf("failed to run %s: %w", c.name, runErr)
+	}
+
+	message := fmt.Sprintf("%s failed with exit code %d", c.name, exitErr.ExitCode())
+	if lastLine != "" {
+		message += ": " + lastLine
+	}
+
+	switch exitErr.ExitCode() {
+	case exitTempFail:
+		return fmt.Errorf("%w: %s", ErrTemporary, message)
+	case exitNoPerm:
+		return fmt.Errorf("%w: %s", ErrUnauthorized, message)
+	default:
+		return errors.New(message)
+	}
+}
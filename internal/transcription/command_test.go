@@ -0,0 +1,114 @@
+package transcription
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/d-mozulyov/vox/pkg/config"
+)
+
+// newFakeCommand creates a command transcriber running testdata/fake_asr.sh
+// in the given FAKE_ASR_MODE
+func newFakeCommand(t *testing.T, mode string, provider config.ProviderConfig) Transcriber {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script fixture requires a POSIX shell")
+	}
+	t.Setenv("FAKE_ASR_MODE", mode)
+
+	script, err := filepath.Abs(filepath.Join("testdata", "fake_asr.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider.Backend = config.BackendCommand
+	provider.Command = []string{"/bin/sh", script}
+	provider.Retry = config.RetryConfig{MaxAttempts: 1, AttemptTimeoutSeconds: 1}
+
+	transcriber, err := New(config.TranscriptionConfig{
+		Provider: provider,
+		Language: "en",
+		Glossary: []string{"Vox", "Mistral"},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return transcriber
+}
+
+// TestCommandClient tests that audio goes to stdin and context to env vars
+func TestCommandClient(t *testing.T) {
+	transcriber := newFakeCommand(t, "", config.ProviderConfig{})
+
+	var deltas []string
+	result, err := transcriber.Transcribe(context.Background(), testRequest, func(text string) error {
+		deltas = append(deltas, text)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Transcribe failed: %v", err)
+	}
+
+	// 1600 samples as WAV: 44-byte header + 3200 bytes of data
+	expected := "3244 bytes, wav 16000 Hz, language en, glossary Vox,Mistral"
+	if result.Text != expected {
+		t.Errorf("Expected %q, got %q", expected, result.Text)
+	}
+	if result.Backend != "sh" {
+		t.Errorf("Expected backend sh, got %q", result.Backend)
+	}
+	if len(deltas) != 1 || deltas[0] != expected {
+		t.Errorf("Expected the full text in one delta, got %q", deltas)
+	}
+}
+
+// TestCommandClientPCMContextFile tests raw PCM audio with the context in a JSON file
+func TestCommandClientPCMContextFile(t *testing.T) {
+	transcriber := newFakeCommand(t, "file", config.ProviderConfig{AudioFormat: "pcm", ContextMode: "file"})
+
+	result, err := transcriber.Transcribe(context.Background(), testRequest, nil)
+	if err != nil {
+		t.Fatalf("Transcribe failed: %v", err)
+	}
+
+	var values commandContext
+	if err := json.Unmarshal([]byte(result.Text), &values); err != nil {
+		t.Fatalf("Output is not the context file: %q", result.Text)
+	}
+	if values.AudioFormat != "pcm" || values.SampleRate != 16000 || values.Language != "en" || len(values.Glossary) != 2 {
+		t.Errorf("Unexpected context: %+v", values)
+	}
+}
+
+// TestCommandClientErrors tests exit code mapping and the timeout
+func TestCommandClientErrors(t *testing.T) {
+	tests := []struct {
+		mode     string
+		sentinel error
+		message  string
+	}{
+		{"fail", nil, "exit code 1: model not found"},
+		{"tempfail", ErrTemporary, "exit code 75: server busy"},
+		{"noperm", ErrUnauthorized, "exit code 77"},
+		{"sleep", ErrTemporary, "timed out"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			_, err := newFakeCommand(t, tt.mode, config.ProviderConfig{}).Transcribe(context.Background(), testRequest, nil)
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+			if tt.sentinel != nil && !errors.Is(err, tt.sentinel) {
+				t.Errorf("Expected %v, got %v", tt.sentinel, err)
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("Expected error containing %q, got %v", tt.message, err)
+			}
+		})
+	}
+}
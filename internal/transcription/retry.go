@@ -0,0 +1,161 @@
+package transcription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/d-mozulyov/vox/internal/platform"
+	"github.com/d-mozulyov/vox/pkg/config"
+)
+
+// Default retry policy, used for zero values in config.RetryConfig
+const (
+	defaultMaxAttempts    = 3
+	defaultInitialDelay   = 500 * time.Millisecond
+	defaultMaxDelay       = 5 * time.Second
+	defaultAttemptTimeout = 30 * time.Second
+)
+
+// clock abstracts time, so retry delays can be tested deterministically
+type clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock implements clock with the time package
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// retryPolicy holds a provider's retry settings with defaults applied
+type retryPolicy struct {
+	maxAttempts    int
+	initialDelay   time.Duration
+	maxDelay       time.Duration
+	attemptTimeout time.Duration
+}
+
+// newRetryPolicy creates a retry policy from the configuration
+func newRetryPolicy(cfg config.RetryConfig) retryPolicy {
+	policy := retryPolicy{
+		maxAttempts:    cfg.MaxAttempts,
+		initialDelay:   time.Duration(cfg.InitialDelayMs) * time.Millisecond,
+		maxDelay:       time.Duration(cfg.MaxDelayMs) * time.Millisecond,
+		attemptTimeout: time.Duration(cfg.AttemptTimeoutSeconds) * time.Second,
+	}
+	if policy.maxAttempts <= 0 {
+		policy.maxAttempts = defaultMaxAttempts
+	}
+	if policy.initialDelay <= 0 {
+		policy.initialDelay = defaultInitialDelay
+	}
+	if policy.maxDelay <= 0 {
+		policy.maxDelay = defaultMaxDelay
+	}
+	if policy.attemptTimeout <= 0 {
+		policy.attemptTimeout = defaultAttemptTimeout
+	}
+	return policy
+}
+
+// retrier implements Transcriber by retrying a provider's temporary failures
+// Each attempt has its own timeout; retries stop when the caller's deadline
+// (the end of the Transcribing state) would pass before the next attempt
+type retrier struct {
+	Transcriber
+	name   string
+	policy retryPolicy
+	clock  clock
+	jitter func(delay time.Duration) time.Duration
+}
+
+// newRetrier wraps a provider's transcriber with its retry policy
+func newRetrier(transcriber Transcriber, name string, cfg config.RetryConfig) Transcriber {
+	return &retrier{
+		Transcriber: transcriber,
+		name:        name,
+		policy:      newRetryPolicy(cfg),
+		clock:       realClock{},
+		jitter:      equalJitter,
+	}
+}
+
+// Transcribe runs attempts until one succeeds, fails permanently or the attempts run out
+func (r *retrier) Transcribe(ctx context.Context, req Request, onDelta func(text string) error) (Result, error) {
+	logger := platform.GetLogger()
+
+	for attempt := 1; ; attempt++ {
+		// Text passed to onDelta cannot be taken back, so a failure after it is final
+		delivered := false
+		result, err := r.attempt(ctx, req, func(text string) error {
+			delivered = true
+			if onDelta != nil {
+				return onDelta(text)
+			}
+			return nil
+		})
+		if err == nil {
+			return result, nil
+		}
+		if delivered || !errors.Is(err, ErrTemporary) || attempt >= r.policy.maxAttempts {
+			return Result{}, err
+		}
+
+		delay := r.delay(attempt, err)
+		if deadline, ok := ctx.Deadline(); ok && r.clock.Now().Add(delay).After(deadline) {
+			logger.Warn("Not retrying %s: next attempt in %v would miss the deadline", r.name, delay)
+			return Result{}, err
+		}
+
+		logger.Warn("Attempt %d/%d by %s failed: %v. Retrying in %v", attempt, r.policy.maxAttempts, r.name, err, delay)
+
+		select {
+		case <-ctx.Done():
+			return Result{}, fmt.Errorf("transcription cancelled: %w", ctx.Err())
+		case <-r.clock.After(delay):
+		}
+	}
+}
+
+// attempt runs a single attempt with the per-attempt timeout
+// An attempt that runs out of its own time is a temporary failure
+func (r *retrier) attempt(ctx context.Context, req Request, onDelta func(text string) error) (Result, error) {
+	attemptCtx, cancel := context.WithTimeout(ctx, r.policy.attemptTimeout)
+	defer cancel()
+
+	result, err := r.Transcriber.Transcribe(attemptCtx, req, onDelta)
+	if err != nil && ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+		return Result{}, fmt.Errorf("%w: attempt timed out after %v: %w", ErrTemporary, r.policy.attemptTimeout, err)
+	}
+	return result, err
+}
+
+// delay returns the wait before the next attempt: Retry-After if the server
+// sent it, otherwise jittered exponential backoff
+func (r *retrier) delay(attempt int, err error) time.Duration {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+		return statusErr.RetryAfter
+	}
+
+	delay := r.policy.initialDelay
+	for i := 1; i < attempt && delay < r.policy.maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > r.policy.maxDelay {
+		delay = r.policy.maxDelay
+	}
+
+	return r.jitter(delay)
+}
+
+// equalJitter returns a random delay between half of the delay and the full delay
+// Spreads retries of many clients while keeping the backoff growing
+func equalJitter(delay time.Duration) time.Duration {
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
@@ -0,0 +1,203 @@
+package transcription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/d-mozulyov/vox/pkg/config"
+)
+
+// fakeClock records requested delays and fires them immediately
+type fakeClock struct {
+	now    time.Time
+	delays []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.delays = append(c.delays, d)
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+// scriptedResponse is a response of the scripted server
+type scriptedResponse struct {
+	status     int
+	retryAfter string
+	delay      time.Duration
+}
+
+// newScriptedServer answers requests with the scripted responses in order
+// and with a transcript once the script is over
+func newScriptedServer(t *testing.T, script []scriptedResponse, requests *int) *httptest.Server {
+	var mutex sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		*requests++
+		index := *requests - 1
+		mutex.Unlock()
+
+		if index >= len(script) {
+			fmt.Fprint(w, `{"text":"Hello."}`)
+			return
+		}
+		response := script[index]
+		time.Sleep(response.delay)
+		if response.retryAfter != "" {
+			w.Header().Set("Retry-After", response.retryAfter)
+		}
+		http.Error(w, http.StatusText(response.status), response.status)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newTestRetrier creates a retrier for a whisper provider with a fake clock and no jitter
+func newTestRetrier(server *httptest.Server, retry config.RetryConfig) (*retrier, *fakeClock) {
+	provider := config.ProviderConfig{BaseURL: server.URL, Model: "whisper-1"}
+	clock := &fakeClock{now: time.Now()}
+	r := newRetrier(newWhisperClient(provider, config.TranscriptionConfig{}), "test", retry).(*retrier)
+	r.clock = clock
+	r.jitter = func(delay time.Duration) time.Duration { return delay }
+	return r, clock
+}
+
+// TestRetryBackoff tests exponential backoff and Retry-After on 429 and 503
+func TestRetryBackoff(t *testing.T) {
+	var requests int
+	server := newScriptedServer(t, []scriptedResponse{
+		{status: http.StatusServiceUnavailable},
+		{status: http.StatusBadGateway},
+		{status: http.StatusTooManyRequests, retryAfter: "3"},
+		{status: http.StatusInternalServerError},
+	}, &requests)
+
+	r, clock := newTestRetrier(server, config.RetryConfig{MaxAttempts: 5, InitialDelayMs: 100, MaxDelayMs: 300})
+	result, err := r.Transcribe(context.Background(), testRequest, nil)
+	if err != nil {
+		t.Fatalf("Transcribe failed: %v", err)
+	}
+	if result.Text != "Hello." || requests != 5 {
+		t.Errorf("Expected success on attempt 5, got %q after %d requests", result.Text, requests)
+	}
+
+	// 100 ms, 200 ms, Retry-After 3 s, then capped at 300 ms
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 3 * time.Second, 300 * time.Millisecond}
+	if fmt.Sprint(clock.delays) != fmt.Sprint(expected) {
+		t.Errorf("Expected delays %v, got %v", expected, clock.delays)
+	}
+}
+
+// TestRetryGiveUp tests that retries stop after the last attempt and on permanent errors
+func TestRetryGiveUp(t *testing.T) {
+	var requests int
+	server := newScriptedServer(t, []scriptedResponse{
+		{status: http.StatusServiceUnavailable},
+		{status: http.StatusServiceUnavailable},
+		{status: http.StatusServiceUnavailable},
+	}, &requests)
+
+	r, _ := newTestRetrier(server, config.RetryConfig{MaxAttempts: 2})
+	if _, err := r.Transcribe(context.Background(), testRequest, nil); !errors.Is(err, ErrTemporary) {
+		t.Errorf("Expected temporary error, got %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected 2 attempts, got %d", requests)
+	}
+
+	requests = 0
+	server = newScriptedServer(t, []scriptedResponse{{status: http.StatusUnauthorized}}, &requests)
+	r, clock := newTestRetrier(server, config.RetryConfig{})
+	if _, err := r.Transcribe(context.Background(), testRequest, nil); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Expected unauthorized error, got %v", err)
+	}
+	if requests != 1 || len(clock.delays) != 0 {
+		t.Errorf("Expected no retries, got %d requests", requests)
+	}
+}
+
+// TestRetryDeadline tests that no retry is made if it would miss the overall deadline
+func TestRetryDeadline(t *testing.T) {
+	var requests int
+	server := newScriptedServer(t, []scriptedResponse{
+		{status: http.StatusTooManyRequests, retryAfter: "120"},
+	}, &requests)
+
+	r, clock := newTestRetrier(server, config.RetryConfig{})
+	ctx, cancel := context.WithDeadline(context.Background(), clock.now.Add(10*time.Second))
+	defer cancel()
+
+	var statusErr *StatusError
+	if _, err := r.Transcribe(ctx, testRequest, nil); !errors.As(err, &statusErr) || statusErr.RetryAfter != 120*time.Second {
+		t.Errorf("Expected 429 with Retry-After, got %v", err)
+	}
+	if requests != 1 || len(clock.delays) != 0 {
+		t.Errorf("Expected no retry, got %d requests", requests)
+	}
+}
+
+// TestRetryAttemptTimeout tests that a slow attempt times out and is retried
+func TestRetryAttemptTimeout(t *testing.T) {
+	var requests int
+	server := newScriptedServer(t, []scriptedResponse{
+		{status: http.StatusOK, delay: 500 * time.Millisecond},
+	}, &requests)
+
+	r, _ := newTestRetrier(server, config.RetryConfig{})
+	r.policy.attemptTimeout = 50 * time.Millisecond
+
+	result, err := r.Transcribe(context.Background(), testRequest, nil)
+	if err != nil {
+		t.Fatalf("Transcribe failed: %v", err)
+	}
+	if result.Text != "Hello." || requests != 2 {
+		t.Errorf("Expected success on the second attempt, got %q after %d requests", result.Text, requests)
+	}
+}
+
+// TestParseRetryAfter tests both Retry-After formats
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := map[string]time.Duration{
+		"":                              0,
+		"7":                             7 * time.Second,
+		"-1":                            0,
+		"soon":                          0,
+		"Fri, 02 Jan 2026 03:04:35 GMT": 30 * time.Second,
+		"Fri, 02 Jan 2026 03:04:00 GMT": 0,
+	}
+	for value, expected := range tests {
+		if got := parseRetryAfter(value, now); got != expected {
+			t.Errorf("parseRetryAfter(%q): expected %v, got %v", value, expected, got)
+		}
+	}
+}
+
+// TestRetryable tests which failures keep the audio for a later attempt
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected bool
+	}{
+		{&StatusError{StatusCode: 503}, true},
+		{fmt.Errorf("all providers failed, last error: %w", ErrTemporary), true},
+		{fmt.Errorf("transcription cancelled: %w", context.DeadlineExceeded), true},
+		{fmt.Errorf("transcription cancelled: %w", context.Canceled), false},
+		{&StatusError{StatusCode: 401}, false},
+		{errors.New("bad request"), false},
+	}
+	for _, tt := range tests {
+		if got := Retryable(tt.err); got != tt.expected {
+			t.Errorf("Retryable(%v) = %v, expected %v", tt.err, got, tt.expected)
+		}
+	}
+}
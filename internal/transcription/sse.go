@@ -0,0 +1,48 @@
+package transcription
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// doneMarker is the data of the last server-sent event of a completion stream
+const doneMarker = "[DONE]"
+
+// readEvents reads server-sent events and calls onData with the data of each event
+// Returns nil after the [DONE] marker and io.ErrUnexpectedEOF if the stream
+// ends without it
+func readEvents(r io.Reader, onData func(data string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case line == "":
+			// An empty line ends the event
+			if len(lines) == 0 {
+				continue
+			}
+			data := strings.Join(lines, "\n")
+			lines = lines[:0]
+			if data == doneMarker {
+				return nil
+			}
+			if err := onData(data); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "data:"):
+			lines = append(lines, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		default:
+			// Comments (keep-alive) and other fields are ignored
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return io.ErrUnexpectedEOF
+}
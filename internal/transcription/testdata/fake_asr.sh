@@ -0,0 +1,28 @@
+#!/bin/sh
+# Fake ASR command for command backend tests
+# Reads the audio from stdin and behaves according to FAKE_ASR_MODE
+size=$(wc -c | tr -d ' ')
+echo "received $size bytes" >&2
+
+case "$FAKE_ASR_MODE" in
+fail)
+    echo "model not found" >&2
+    exit 1
+    ;;
+tempfail)
+    echo "server busy" >&2
+    exit 75
+    ;;
+noperm)
+    exit 77
+    ;;
+sleep)
+    exec sleep 10
+    ;;
+file)
+    cat "$VOX_CONTEXT_FILE"
+    ;;
+*)
+    echo "$size bytes, $VOX_AUDIO_FORMAT $VOX_SAMPLE_RATE Hz, language $VOX_LANGUAGE, glossary $VOX_GLOSSARY"
+    ;;
+esac
@@ -0,0 +1,232 @@
+// Package transcription converts recorded audio to text using AI backends.
+package transcription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/d-mozulyov/vox/internal/platform"
+	"github.com/d-mozulyov/vox/pkg/config"
+)
+
+// Request holds the audio to transcribe
+type Request struct {
+	Samples    []int16 // 16-bit mono PCM
+	SampleRate int
+}
+
+// Result holds a finished transcription
+type Result struct {
+	Text    string
+	Backend string
+	Model   string
+	Usage   Usage // zero if the provider does not report usage
+}
+
+// Usage holds the token counts reported by a provider (the usage block of chat completions)
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// Transcriber defines the interface for speech-to-text backends
+type Transcriber interface {
+	// Transcribe converts the audio to text
+	// Text is passed to onDelta as it arrives: in fragments when the response
+	// is streamed, otherwise in one piece. An error from onDelta aborts the
+	// transcription. The Result always holds the full text.
+	Transcribe(ctx context.Context, req Request, onDelta func(text string) error) (Result, error)
+}
+
+var (
+	// ErrTemporary marks failures that may succeed when retried later or on another backend
+	ErrTemporary = errors.New("temporary failure")
+	// ErrUnauthorized marks failures that need the user to fix credentials or permissions
+	ErrUnauthorized = errors.New("unauthorized")
+)
+
+// StreamError is returned when a streamed response breaks midway
+// Text holds everything that was received (and passed to onDelta) before the failure
+type StreamError struct {
+	Text string
+	Err  error
+}
+
+// Error returns the error message
+func (e *StreamError) Error() string {
+	return fmt.Sprintf("response stream broken after %d characters: %v", len(e.Text), e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *StreamError) Unwrap() error {
+	return e.Err
+}
+
+// StatusError is returned for HTTP responses other than 200 OK
+// Rate limits (429) and server errors (5xx) match ErrTemporary,
+// authentication errors (401, 403) match ErrUnauthorized
+type StatusError struct {
+	StatusCode int
+	Status     string
+	Message    string
+	RetryAfter time.Duration // from the Retry-After header of 429 and 503 responses, 0 if absent
+}
+
+// Error returns the error message
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("transcription request failed: %s: %s", e.Status, e.Message)
+}
+
+// Is reports whether the status matches ErrTemporary or ErrUnauthorized
+func (e *StatusError) Is(target error) bool {
+	switch target {
+	case ErrTemporary:
+		return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	default:
+		return false
+	}
+}
+
+// Retryable reports whether a failed transcription may succeed later with the same audio:
+// a temporary failure or the Transcribing state's deadline running out
+func Retryable(err error) bool {
+	return errors.Is(err, ErrTemporary) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// New creates a transcriber for the configured provider and its fallbacks
+// Each provider retries temporary failures (see retrier); with fallbacks,
+// the providers are then tried in order (see chain).
+// The caller's context deadline limits the whole transcription.
+func New(cfg config.TranscriptionConfig) (Transcriber, error) {
+	logger := platform.GetLogger()
+
+	primary, err := newProviderTranscriber(cfg.Provider, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.Fallbacks) == 0 {
+		return primary.Transcriber, nil
+	}
+
+	links := []chainLink{primary}
+	for i, provider := range cfg.Fallbacks {
+		link, err := newProviderTranscriber(provider, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("fallback %d: %w", i+1, err)
+		}
+		// Audio from a local provider must never reach a cloud provider
+		if primary.local && !link.local {
+			logger.Warn("Fallback %s skipped: local provider %s never falls back to cloud providers", link.name, primary.name)
+			continue
+		}
+		links = append(links, link)
+	}
+
+	if len(links) == 1 {
+		return primary.Transcriber, nil
+	}
+	return newChain(links), nil
+}
+
+// newProviderTranscriber creates the transcriber for a single provider with its retry policy
+func newProviderTranscriber(provider config.ProviderConfig, cfg config.TranscriptionConfig) (chainLink, error) {
+	provider, err := provider.Resolve()
+	if err != nil {
+		return chainLink{}, err
+	}
+
+	transcriber, err := newBackend(provider, cfg)
+	if err != nil {
+		return chainLink{}, err
+	}
+
+	name := providerName(provider)
+	return chainLink{
+		Transcriber: newRetrier(transcriber, name, provider.Retry),
+		name:        name,
+		local:       provider.Local,
+	}, nil
+}
+
+// newBackend creates the transcriber for the provider's backend
+func newBackend(provider config.ProviderConfig, cfg config.TranscriptionConfig) (Transcriber, error) {
+	switch provider.Backend {
+	case config.BackendChat:
+		return newChatClient(provider, cfg), nil
+	case config.BackendWhisper, config.BackendInference:
+		return newWhisperClient(provider, cfg), nil
+	case config.BackendCommand:
+		return newCommandClient(provider, cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown transcription backend: %s", provider.Backend)
+	}
+}
+
+// providerName returns the name reported in results:
+// the preset, the base URL or the command name
+func providerName(provider config.ProviderConfig) string {
+	switch {
+	case provider.Preset != "":
+		return provider.Preset
+	case provider.BaseURL != "":
+		return provider.BaseURL
+	case len(provider.Command) > 0:
+		return filepath.Base(provider.Command[0])
+	default:
+		return provider.Backend
+	}
+}
+
+// checkResponse returns a *StatusError for non-200 responses
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	statusErr := &StatusError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Message:    strings.TrimSpace(string(message)),
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		statusErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	}
+	return statusErr
+}
+
+// parseRetryAfter parses a Retry-After header: delay in seconds or an HTTP date
+// Returns 0 if the header is absent or invalid
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
+// requestError wraps an error of sending a request
+// Network errors and client timeouts are temporary; cancellation of ctx is not
+func requestError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return fmt.Errorf("transcription request cancelled: %w", ctx.Err())
+	}
+	return fmt.Errorf("%w: transcription request failed: %w", ErrTemporary, err)
+}
@@ -0,0 +1,197 @@
+package transcription
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/d-mozulyov/vox/internal/platform"
+	"github.com/d-mozulyov/vox/internal/wav"
+	"github.com/d-mozulyov/vox/pkg/config"
+)
+
+// whisperClient implements Transcriber by uploading the recording as a multipart WAV file:
+// to a Whisper-style /audio/transcriptions endpoint or to a self-hosted server
+// such as whisper.cpp (/inference) with its own field names
+type whisperClient struct {
+	name           string
+	url            string
+	apiKey         string
+	model          string
+	language       string
+	prompt         string
+	responseFormat string
+	fields         map[string]string // standard form field name -> name sent
+	textField      []string          // path of the transcript in a JSON response
+	httpClient     *http.Client
+}
+
+// newWhisperClient creates a transcriber for a multipart transcription endpoint
+func newWhisperClient(provider config.ProviderConfig, cfg config.TranscriptionConfig) Transcriber {
+	path := provider.Path
+	if path == "" {
+		path = "/audio/transcriptions"
+		if provider.Backend == config.BackendInference {
+			path = "/inference"
+		}
+	}
+
+	responseFormat := provider.ResponseFormat
+	if responseFormat == "" {
+		responseFormat = "json"
+	}
+
+	textField := provider.TextField
+	if textField == "" {
+		textField = "text"
+	}
+
+	return &whisperClient{
+		name:     providerName(provider),
+		url:      strings.TrimSuffix(provider.BaseURL, "/") + "/" + strings.TrimPrefix(path, "/"),
+		apiKey:   provider.APIKey,
+		model:    provider.Model,
+		language: cfg.Language,
+		// Whisper uses the prompt as preceding text, so a list of terms works best
+		prompt:         strings.Join(cfg.Glossary, ", "),
+		responseFormat: responseFormat,
+		fields:         provider.Fields,
+		textField:      strings.Split(textField, "."),
+		httpClient:     &http.Client{},
+	}
+}
+
+// fieldName returns the form field name sent for a standard field ("" omits the field)
+func (c *whisperClient) fieldName(name string) string {
+	if renamed, ok := c.fields[name]; ok {
+		return renamed
+	}
+	return name
+}
+
+// Transcribe uploads the audio to the endpoint and returns the transcript
+// The response is not streamed, so onDelta receives the full text at once
+func (c *whisperClient) Transcribe(ctx context.Context, req Request, onDelta func(text string) error) (Result, error) {
+	logger := platform.GetLogger()
+
+	body, contentType, err := c.newRequestBody(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, body)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", contentType)
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	logger.Info("Uploading %.1f s of audio to %s (model: %s)",
+		float64(len(req.Samples))/float64(req.SampleRate), c.url, c.model)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return Result{}, requestError(ctx, err)
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp); err != nil {
+		return Result{}, err
+	}
+
+	text, err := c.readResponse(resp.Body)
+	if err != nil {
+		return Result{}, err
+	}
+
+	if onDelta != nil && text != "" {
+		if err := onDelta(text); err != nil {
+			return Result{}, err
+		}
+	}
+
+	return Result{Text: text, Backend: c.name, Model: c.model}, nil
+}
+
+// newRequestBody builds the multipart form with the audio file and parameters
+// Returns the body and its content type
+func (c *whisperClient) newRequestBody(req Request) (io.Reader, string, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+
+	// The audio file cannot be omitted
+	fileField := c.fieldName("file")
+	if fileField == "" {
+		fileField = "file"
+	}
+	file, err := form.CreateFormFile(fileField, "audio.wav")
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := file.Write(wav.Encode(req.Samples, req.SampleRate)); err != nil {
+		return nil, "", err
+	}
+
+	fields := []struct{ name, value string }{
+		{"model", c.model},
+		{"language", c.language},
+		{"prompt", c.prompt},
+		{"response_format", c.responseFormat},
+	}
+	for _, field := range fields {
+		name := c.fieldName(field.name)
+		if name == "" || field.value == "" {
+			continue
+		}
+		if err := form.WriteField(name, field.value); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := form.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return &body, form.FormDataContentType(), nil
+}
+
+// readResponse extracts the transcript: plain text for "text",
+// the text field of a JSON object for other formats
+func (c *whisperClient) readResponse(body io.Reader) (string, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if c.responseFormat == "text" {
+		return strings.TrimSpace(string(data)), nil
+	}
+
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	// Walk the dot-separated path, e.g. "result.text"
+	for _, key := range c.textField {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return "", fmt.Errorf("response has no %q field", strings.Join(c.textField, "."))
+		}
+		value = object[key]
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("response field %q is not a text", strings.Join(c.textField, "."))
+	}
+
+	return strings.TrimSpace(text), nil
+}
@@ -0,0 +1,165 @@
+package transcription
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/d-mozulyov/vox/pkg/config"
+)
+
+// TestWhisperClient tests the multipart request and JSON response parsing
+func TestWhisperClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/audio/transcriptions" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer test-key" {
+			t.Errorf("Unexpected Authorization header: %q", auth)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("Invalid multipart form: %v", err)
+		}
+
+		expected := map[string]string{
+			"model":           "whisper-1",
+			"language":        "en",
+			"prompt":          "Vox, Mistral",
+			"response_format": "json",
+		}
+		for name, value := range expected {
+			if got := r.FormValue(name); got != value {
+				t.Errorf("Field %s: expected %q, got %q", name, value, got)
+			}
+		}
+
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("Audio file missing: %v", err)
+		}
+		defer file.Close()
+		data, _ := io.ReadAll(file)
+		if !strings.HasPrefix(string(data), "RIFF") {
+			t.Error("Audio is not a WAV file")
+		}
+
+		fmt.Fprint(w, `{"text":" Hello Vox. "}`)
+	}))
+	defer server.Close()
+
+	transcriber, err := New(config.TranscriptionConfig{
+		Provider: config.ProviderConfig{
+			Backend: config.BackendWhisper,
+			BaseURL: server.URL + "/v1",
+			APIKey:  "test-key",
+			Model:   "whisper-1",
+		},
+		Language: "en",
+		Glossary: []string{"Vox", "Mistral"},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	var deltas []string
+	result, err := transcriber.Transcribe(context.Background(), testRequest, func(text string) error {
+		deltas = append(deltas, text)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Transcribe failed: %v", err)
+	}
+	if result.Text != "Hello Vox." || result.Model != "whisper-1" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if len(deltas) != 1 || deltas[0] != "Hello Vox." {
+		t.Errorf("Expected the full text in one delta, got %q", deltas)
+	}
+}
+
+// TestWhisperClientTextFormat tests the plain text response format
+func TestWhisperClientTextFormat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("Invalid multipart form: %v", err)
+		}
+		if r.FormValue("prompt") != "" || r.FormValue("language") != "" {
+			t.Error("Expected empty fields to be omitted")
+		}
+		fmt.Fprint(w, "Hello world.\n")
+	}))
+	defer server.Close()
+
+	provider := config.ProviderConfig{BaseURL: server.URL, Model: "whisper-1", ResponseFormat: "text"}
+	result, err := newWhisperClient(provider, config.TranscriptionConfig{}).Transcribe(context.Background(), testRequest, nil)
+	if err != nil {
+		t.Fatalf("Transcribe failed: %v", err)
+	}
+	if result.Text != "Hello world." {
+		t.Errorf("Expected %q, got %q", "Hello world.", result.Text)
+	}
+}
+
+// TestInferenceClient tests a whisper.cpp-style server with renamed fields
+// and a nested transcript in the response
+func TestInferenceClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/inference" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("Invalid multipart form: %v", err)
+		}
+		if r.FormValue("lang") != "de" || r.FormValue("language") != "" {
+			t.Error("Expected language to be sent as \"lang\"")
+		}
+		if r.FormValue("prompt") != "" {
+			t.Error("Expected prompt to be omitted")
+		}
+		if _, _, err := r.FormFile("audio"); err != nil {
+			t.Errorf("Audio file missing: %v", err)
+		}
+		fmt.Fprint(w, `{"result":{"text":"Hallo Welt."}}`)
+	}))
+	defer server.Close()
+
+	transcriber, err := New(config.TranscriptionConfig{
+		Provider: config.ProviderConfig{
+			Preset:    "local",
+			BaseURL:   server.URL,
+			Fields:    map[string]string{"file": "audio", "language": "lang", "prompt": ""},
+			TextField: "result.text",
+		},
+		Language: "de",
+		Glossary: []string{"Vox"},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	result, err := transcriber.Transcribe(context.Background(), testRequest, nil)
+	if err != nil {
+		t.Fatalf("Transcribe failed: %v", err)
+	}
+	if result.Text != "Hallo Welt." || result.Backend != "local" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+}
+
+// TestWhisperClientHTTPError tests that HTTP errors are returned with the status
+func TestWhisperClientHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":{"message":"Rate limit reached"}}`, http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	provider := config.ProviderConfig{BaseURL: server.URL, Model: "whisper-1"}
+	_, err := newWhisperClient(provider, config.TranscriptionConfig{}).Transcribe(context.Background(), testRequest, nil)
+	if err == nil || !strings.Contains(err.Error(), "429") {
+		t.Errorf("Expected 429 error, got %v", err)
+	}
+}
@@ -21,6 +21,8 @@ package tray
 
 import (
 	"fmt"
+	"math"
+	"sync"
 
 	"fyne.io/systray"
 	"github.com/d-mozulyov/vox/internal/platform"
@@ -43,6 +45,56 @@ type TrayManager interface {
 	// isRecording: true for "Stop", false for "Start"
 	UpdateToggleMenuItem(isRecording bool)
 
+	// SetCancelHandler adds the Cancel menu item, which calls handler on click
+	// The item starts disabled, see SetCancelEnabled. Must be called before Run
+	SetCancelHandler(handler func())
+
+	// SetCancelEnabled enables the Cancel menu item while there is something to cancel
+	SetCancelEnabled(enabled bool)
+
+	// SetSupportBundleHandler adds the Save Support Bundle menu item, which calls handler on click
+	// Must be called before Run
+	SetSupportBundleHandler(handler func())
+
+	// SetPreRollHandler adds the pre-roll checkbox to the menu
+	// enabled is the initial state; handler is called with the requested state on click
+	// and the checkbox only changes if it returns nil. Must be called before Run
+	SetPreRollHandler(enabled bool, handler func(enabled bool) error)
+
+	// SetIncognitoHandler adds the incognito checkbox to the menu
+	// Same contract as SetPreRollHandler. Must be called before Run
+	SetIncognitoHandler(enabled bool, handler func(enabled bool) error)
+
+	// SetSoundThemeHandler adds the Sound Theme submenu listing themes, with selected checked
+	// handler is called with the clicked theme and the selection only changes if it returns nil.
+	// Must be called before Run
+	SetSoundThemeHandler(themes []string, selected string, handler func(theme string) error)
+
+	// SetVolumeHandler adds the Volume submenu (Off, 25%, 50%, 75%, 100%) with the level
+	// nearest to volume checked; handler is called with the clicked level from 0 to 1.
+	// Same contract as SetSoundThemeHandler. Must be called before Run
+	SetVolumeHandler(volume float64, handler func(volume float64) error)
+
+	// SetRecoveryHandler adds the submenu of recordings left unfinished by a crash,
+	// with Transcribe and Discard items that call the handlers on click.
+	// The submenu is hidden until SetRecoverable reports any. Must be called before Run
+	SetRecoveryHandler(transcribe func(), discard func())
+
+	// SetRecoverable shows the number of unfinished recordings, 0 hides the submenu
+	SetRecoverable(count int)
+
+	// SetQueueLength shows the number of recordings waiting in the offline queue
+	// The menu item and tooltip note are hidden when the queue is empty
+	SetQueueLength(length int)
+
+	// SetUsageSummary shows today's usage in the menu, e.g. "3 requests, 1.5 min of audio"
+	// An empty summary hides the menu item
+	SetUsageSummary(summary string)
+
+	// SetStatus shows a message in the tooltip, e.g. where a queued transcript went
+	// An empty message clears it
+	SetStatus(message string)
+
 	// Run starts the tray event loop (blocking call)
 	// This should be called in the main goroutine
 	Run()
@@ -56,11 +108,57 @@ type trayManager struct {
 	onReady        func()
 	onExit         func()
 	onToggleRecord func() // Callback for Start/Stop button
+	onCancel       func() // Callback for Cancel button, nil if not set
+	onSupport      func() // Callback for Save Support Bundle button, nil if not set
+	onRecover      func() // Callback for Transcribe of unfinished recordings, nil if not set
+	onDiscard      func() // Callback for Discard of unfinished recordings
+
+	// Optional checkboxes, nil if no handler was set
+	preRoll   *checkbox
+	incognito *checkbox
+
+	// Optional submenus with one selected option, nil if no handler was set
+	soundTheme *choice
+	volume     *choice
 
 	// Menu items
 	menuToggle   *systray.MenuItem
+	menuCancel   *systray.MenuItem
+	menuQueue    *systray.MenuItem
+	menuRecover  *systray.MenuItem
+	menuUsage    *systray.MenuItem
+	menuSupport  *systray.MenuItem
 	menuSettings *systray.MenuItem
 	menuExit     *systray.MenuItem
+
+	queueLength  int
+	recoverable  int
+	usageSummary string
+	status       string
+
+	// mutex guards checkbox states, choices, queue length, unfinished recordings, usage summary and status
+	mutex sync.Mutex
+}
+
+// checkbox is an optional checkable menu item backed by a handler
+type checkbox struct {
+	name    string // used in log messages
+	title   string
+	tooltip string
+	checked bool
+	handler func(checked bool) error
+	item    *systray.MenuItem
+}
+
+// choice is an optional submenu of checkable options of which one is selected
+type choice struct {
+	name     string // used in log messages
+	title    string
+	tooltip  string
+	options  []string
+	selected string
+	handler  func(option string) error
+	items    []*systray.MenuItem
 }
 
 // NewTrayManager creates a new tray manager instance
@@ -109,6 +207,110 @@ func (tm *trayManager) SetTooltip(text string) error {
 	return nil
 }
 
+// SetCancelHandler adds the Cancel menu item
+func (tm *trayManager) SetCancelHandler(handler func()) {
+	tm.onCancel = handler
+}
+
+// SetCancelEnabled enables or disables the Cancel menu item
+func (tm *trayManager) SetCancelEnabled(enabled bool) {
+	if tm.menuCancel == nil {
+		return
+	}
+
+	if enabled {
+		tm.menuCancel.Enable()
+	} else {
+		tm.menuCancel.Disable()
+	}
+}
+
+// SetSupportBundleHandler adds the Save Support Bundle menu item
+func (tm *trayManager) SetSupportBundleHandler(handler func()) {
+	tm.onSupport = handler
+}
+
+// SetRecoveryHandler adds the submenu of unfinished recordings
+func (tm *trayManager) SetRecoveryHandler(transcribe func(), discard func()) {
+	tm.onRecover = transcribe
+	tm.onDiscard = discard
+}
+
+// SetPreRollHandler adds the pre-roll checkbox to the menu
+func (tm *trayManager) SetPreRollHandler(enabled bool, handler func(enabled bool) error) {
+	tm.preRoll = &checkbox{
+		name:    "Pre-roll",
+		title:   "Pre-roll (microphone always on)",
+		tooltip: "Keep the last moments of audio in memory so the first word is not clipped",
+		checked: enabled,
+		handler: handler,
+	}
+}
+
+// SetIncognitoHandler adds the incognito checkbox to the menu
+func (tm *trayManager) SetIncognitoHandler(enabled bool, handler func(enabled bool) error) {
+	tm.incognito = &checkbox{
+		name:    "Incognito",
+		title:   "Incognito",
+		tooltip: "Do not write audio to disk: no saved recordings, offline queue or crash recovery",
+		checked: enabled,
+		handler: handler,
+	}
+}
+
+// SetSoundThemeHandler adds the Sound Theme submenu to the menu
+func (tm *trayManager) SetSoundThemeHandler(themes []string, selected string, handler func(theme string) error) {
+	tm.soundTheme = &choice{
+		name:     "Sound theme",
+		title:    "Sound Theme",
+		tooltip:  "Sounds played on start, stop, cancel, error and insertion",
+		options:  themes,
+		selected: selected,
+		handler:  handler,
+	}
+}
+
+// volumeLevels are the options of the Volume submenu
+var volumeLevels = []float64{0, 0.25, 0.5, 0.75, 1}
+
+// volumeLabel returns the title of a volume level
+func volumeLabel(level float64) string {
+	if level == 0 {
+		return "Off"
+	}
+	return fmt.Sprintf("%.0f%%", level*100)
+}
+
+// SetVolumeHandler adds the Volume submenu to the menu
+func (tm *trayManager) SetVolumeHandler(volume float64, handler func(volume float64) error) {
+	var options []string
+	selected := ""
+	nearest := math.Inf(1)
+	for _, level := range volumeLevels {
+		options = append(options, volumeLabel(level))
+		if distance := math.Abs(level - volume); distance < nearest {
+			nearest = distance
+			selected = volumeLabel(level)
+		}
+	}
+
+	tm.volume = &choice{
+		name:     "Volume",
+		title:    "Volume",
+		tooltip:  "Volume of the sounds",
+		options:  options,
+		selected: selected,
+		handler: func(option string) error {
+			for _, level := range volumeLevels {
+				if volumeLabel(level) == option {
+					return handler(level)
+				}
+			}
+			return fmt.Errorf("unknown volume %q", option)
+		},
+	}
+}
+
 // Run starts the tray event loop (blocking)
 // This must be called from the main goroutine
 func (tm *trayManager) Run() {
@@ -120,13 +322,69 @@ func (tm *trayManager) onReadyWrapper() {
 	logger := platform.GetLogger()
 
 	// Set initial tooltip
-	systray.SetTooltip("Vox - Voice Input Assistant")
+	tm.updateTooltip()
 	logger.Info("Tray tooltip set")
 
 	// Create menu items
 	tm.menuToggle = systray.AddMenuItem("Start", "Start voice recording")
 	logger.Info("Toggle menu item created (Start)")
 
+	if tm.onCancel != nil {
+		tm.menuCancel = systray.AddMenuItem("Cancel", "Discard the recording or stop the transcription")
+		tm.menuCancel.Disable()
+		go tm.handleCancelClicks()
+		logger.Info("Cancel menu item created (disabled)")
+	}
+
+	for _, cb := range []*checkbox{tm.preRoll, tm.incognito} {
+		if cb == nil {
+			continue
+		}
+		cb.item = systray.AddMenuItemCheckbox(cb.title, cb.tooltip, cb.checked)
+		logger.Info("%s menu item created (enabled: %v)", cb.name, cb.checked)
+		go tm.handleCheckboxClicks(cb)
+	}
+
+	for _, ch := range []*choice{tm.soundTheme, tm.volume} {
+		if ch == nil {
+			continue
+		}
+		menu := systray.AddMenuItem(ch.title, ch.tooltip)
+		for _, option := range ch.options {
+			ch.items = append(ch.items, menu.AddSubMenuItemCheckbox(option, "", option == ch.selected))
+		}
+		logger.Info("%s menu item created (selected: %s)", ch.name, ch.selected)
+		for i := range ch.items {
+			go tm.handleChoiceClicks(ch, i)
+		}
+	}
+
+	// Informational item, shown while recordings wait in the offline queue
+	tm.menuQueue = systray.AddMenuItem("", "Recordings waiting to be transcribed")
+	tm.menuQueue.Disable()
+	tm.updateQueueMenuItem()
+
+	// Submenu of recordings left unfinished by a crash, shown while there are any
+	if tm.onRecover != nil {
+		tm.menuRecover = systray.AddMenuItem("", "Recordings interrupted by a crash or a forced exit")
+		transcribe := tm.menuRecover.AddSubMenuItem("Transcribe", "Transcribe the recordings and copy the text to the clipboard")
+		discard := tm.menuRecover.AddSubMenuItem("Discard", "Delete the recordings")
+		go tm.handleRecoveryClicks(transcribe, discard)
+		tm.updateRecoverMenuItem()
+		logger.Info("Unfinished recordings menu item created")
+	}
+
+	// Informational item with today's usage
+	tm.menuUsage = systray.AddMenuItem("", "Transcription usage today")
+	tm.menuUsage.Disable()
+	tm.updateUsageMenuItem()
+
+	if tm.onSupport != nil {
+		tm.menuSupport = systray.AddMenuItem("Save Support Bundle", "Save version, state history and recent log to a file")
+		go tm.handleSupportClicks()
+		logger.Info("Support bundle menu item created")
+	}
+
 	tm.menuSettings = systray.AddMenuItem("Settings", "Open settings window")
 	tm.menuSettings.Disable() // Placeholder - will be enabled in future
 	logger.Info("Settings menu item created (disabled)")
@@ -173,6 +431,99 @@ func (tm *trayManager) handleMenuClicks() {
 	}
 }
 
+// handleCancelClicks calls the cancel handler on click
+func (tm *trayManager) handleCancelClicks() {
+	logger := platform.GetLogger()
+	for range tm.menuCancel.ClickedCh {
+		logger.Info("Cancel menu item clicked")
+		tm.onCancel()
+	}
+}
+
+// handleSupportClicks calls the support bundle handler on click
+func (tm *trayManager) handleSupportClicks() {
+	logger := platform.GetLogger()
+	for range tm.menuSupport.ClickedCh {
+		logger.Info("Save Support Bundle menu item clicked")
+		tm.onSupport()
+	}
+}
+
+// handleRecoveryClicks calls the recovery handlers on click
+func (tm *trayManager) handleRecoveryClicks(transcribe, discard *systray.MenuItem) {
+	logger := platform.GetLogger()
+	for {
+		select {
+		case <-transcribe.ClickedCh:
+			logger.Info("Transcribe unfinished recordings clicked")
+			tm.onRecover()
+		case <-discard.ClickedCh:
+			logger.Info("Discard unfinished recordings clicked")
+			tm.onDiscard()
+		}
+	}
+}
+
+// handleCheckboxClicks toggles a checkbox on click if its handler accepts the new state
+func (tm *trayManager) handleCheckboxClicks(cb *checkbox) {
+	logger := platform.GetLogger()
+	for range cb.item.ClickedCh {
+		checked := !cb.item.Checked()
+		logger.Info("%s menu item clicked, enabled: %v", cb.name, checked)
+
+		if err := cb.handler(checked); err != nil {
+			logger.Error("Failed to change %s: %v", cb.name, err)
+			continue
+		}
+
+		if checked {
+			cb.item.Check()
+		} else {
+			cb.item.Uncheck()
+		}
+
+		tm.mutex.Lock()
+		cb.checked = checked
+		tm.mutex.Unlock()
+		tm.updateTooltip()
+	}
+}
+
+// handleChoiceClicks selects an option on click if its handler accepts it
+func (tm *trayManager) handleChoiceClicks(ch *choice, index int) {
+	logger := platform.GetLogger()
+	option := ch.options[index]
+	for range ch.items[index].ClickedCh {
+		logger.Info("%s menu item clicked: %s", ch.name, option)
+
+		if err := ch.handler(option); err != nil {
+			logger.Error("Failed to change %s: %v", ch.name, err)
+			// Clicking toggles the check mark on some platforms, so restore it
+			tm.mutex.Lock()
+			tm.checkSelected(ch)
+			tm.mutex.Unlock()
+			continue
+		}
+
+		tm.mutex.Lock()
+		ch.selected = option
+		tm.checkSelected(ch)
+		tm.mutex.Unlock()
+	}
+}
+
+// checkSelected checks the selected option of a choice and unchecks the others
+// Must be called with the mutex held
+func (tm *trayManager) checkSelected(ch *choice) {
+	for i, item := range ch.items {
+		if ch.options[i] == ch.selected {
+			item.Check()
+		} else {
+			item.Uncheck()
+		}
+	}
+}
+
 // UpdateToggleMenuItem updates the Start/Stop menu item based on current state
 func (tm *trayManager) UpdateToggleMenuItem(isRecording bool) {
 	if tm.menuToggle == nil {
@@ -188,6 +539,120 @@ func (tm *trayManager) UpdateToggleMenuItem(isRecording bool) {
 	}
 }
 
+// SetQueueLength shows the number of recordings waiting in the offline queue
+// May be called before the tray is ready; the menu item is updated when it is created
+func (tm *trayManager) SetQueueLength(length int) {
+	tm.mutex.Lock()
+	tm.queueLength = length
+	tm.mutex.Unlock()
+
+	tm.updateQueueMenuItem()
+	tm.updateTooltip()
+}
+
+// SetRecoverable shows the number of unfinished recordings
+// May be called before the tray is ready; the submenu is updated when it is created
+func (tm *trayManager) SetRecoverable(count int) {
+	tm.mutex.Lock()
+	tm.recoverable = count
+	tm.mutex.Unlock()
+
+	tm.updateRecoverMenuItem()
+	tm.updateTooltip()
+}
+
+// updateRecoverMenuItem shows the number of unfinished recordings, or hides the submenu if there are none
+func (tm *trayManager) updateRecoverMenuItem() {
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
+	if tm.menuRecover == nil {
+		return
+	}
+	if tm.recoverable == 0 {
+		tm.menuRecover.Hide()
+		return
+	}
+	tm.menuRecover.SetTitle(fmt.Sprintf("Unfinished recordings: %d", tm.recoverable))
+	tm.menuRecover.Show()
+}
+
+// SetUsageSummary shows today's usage in the menu
+// May be called before the tray is ready; the menu item is updated when it is created
+func (tm *trayManager) SetUsageSummary(summary string) {
+	tm.mutex.Lock()
+	tm.usageSummary = summary
+	tm.mutex.Unlock()
+
+	tm.updateUsageMenuItem()
+}
+
+// updateUsageMenuItem shows the usage summary, or hides the item if there is none
+func (tm *trayManager) updateUsageMenuItem() {
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
+	if tm.menuUsage == nil {
+		return
+	}
+	if tm.usageSummary == "" {
+		tm.menuUsage.Hide()
+		return
+	}
+	tm.menuUsage.SetTitle("Today: " + tm.usageSummary)
+	tm.menuUsage.Show()
+}
+
+// SetStatus shows a message in the tooltip
+func (tm *trayManager) SetStatus(message string) {
+	tm.mutex.Lock()
+	tm.status = message
+	tm.mutex.Unlock()
+
+	tm.updateTooltip()
+}
+
+// updateQueueMenuItem shows the queue length, or hides the item if the queue is empty
+func (tm *trayManager) updateQueueMenuItem() {
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
+	if tm.menuQueue == nil {
+		return
+	}
+	if tm.queueLength == 0 {
+		tm.menuQueue.Hide()
+		return
+	}
+	tm.menuQueue.SetTitle(fmt.Sprintf("Queued recordings: %d", tm.queueLength))
+	tm.menuQueue.Show()
+}
+
+// updateTooltip sets the tooltip, noting privacy-related modes
+// (microphone kept open by pre-roll, incognito), the offline queue and the status message
+func (tm *trayManager) updateTooltip() {
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
+	tooltip := "Vox - Voice Input Assistant"
+	if tm.preRoll != nil && tm.preRoll.checked {
+		tooltip += " (microphone is live: pre-roll)"
+	}
+	if tm.incognito != nil && tm.incognito.checked {
+		tooltip += " (incognito)"
+	}
+	if tm.queueLength > 0 {
+		tooltip += fmt.Sprintf(" (%d queued)", tm.queueLength)
+	}
+	if tm.recoverable > 0 {
+		tooltip += fmt.Sprintf(" (%d unfinished)", tm.recoverable)
+	}
+	if tm.status != "" {
+		tooltip += "\n" + tm.status
+	}
+	systray.SetTooltip(tooltip)
+}
+
 // Quit removes tray icon and exits the application
 func (tm *trayManager) Quit() {
 	systray.Quit()
@@ -0,0 +1,230 @@
+// Package usage keeps daily totals of transcription requests, audio and tokens
+// per provider and model, and estimates their cost from configured prices.
+//
+// Totals are stored as a JSON array in a single file (~/.vox/usage.json).
+package usage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/d-mozulyov/vox/pkg/config"
+)
+
+// DateLayout is the format of entry dates and date filters
+const DateLayout = "2006-01-02"
+
+// Entry holds the totals of one provider and model on one day
+type Entry struct {
+	Date             string  `json:"date"` // local date, see DateLayout
+	Provider         string  `json:"provider"`
+	Model            string  `json:"model,omitempty"`
+	Requests         int     `json:"requests"`
+	AudioSeconds     float64 `json:"audio_seconds"`
+	PromptTokens     int     `json:"prompt_tokens,omitempty"`
+	CompletionTokens int     `json:"completion_tokens,omitempty"`
+	TotalTokens      int     `json:"total_tokens,omitempty"`
+}
+
+// add adds the counters of another entry
+func (e *Entry) add(other Entry) {
+	e.Requests += other.Requests
+	e.AudioSeconds += other.AudioSeconds
+	e.PromptTokens += other.PromptTokens
+	e.CompletionTokens += other.CompletionTokens
+	e.TotalTokens += other.TotalTokens
+}
+
+// Cost estimates the cost of the entry from the price of its model
+// Returns false if the model has no price
+func (e Entry) Cost(prices map[string]config.PriceConfig) (float64, bool) {
+	price, ok := prices[e.Model]
+	if !ok {
+		return 0, false
+	}
+	return e.AudioSeconds/60*price.PerAudioMinute +
+		float64(e.PromptTokens)/1e6*price.PerMillionInputTokens +
+		float64(e.CompletionTokens)/1e6*price.PerMillionOutputTokens, true
+}
+
+// Store keeps usage entries in a JSON file
+type Store struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewStore creates a store backed by the file at path
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Record adds a finished request to the totals of its day, provider and model
+// Requests, audio seconds and tokens are taken from entry; its date is set from at
+func (s *Store) Record(at time.Time, entry Entry) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entries, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	entry.Date = at.Format(DateLayout)
+	found := false
+	for i := range entries {
+		if entries[i].Date == entry.Date && entries[i].Provider == entry.Provider && entries[i].Model == entry.Model {
+			entries[i].add(entry)
+			found = true
+			break
+		}
+	}
+	if !found {
+		entries = append(entries, entry)
+	}
+
+	return s.save(entries)
+}
+
+// Entries returns the entries from one date to another, both inclusive,
+// sorted by date, provider and model. An empty date leaves that end open.
+func (s *Store) Entries(from, to string) ([]Entry, error) {
+	s.mutex.Lock()
+	entries, err := s.load()
+	s.mutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Entry
+	for _, entry := range entries {
+		// Dates in DateLayout compare correctly as strings
+		if (from != "" && entry.Date < from) || (to != "" && entry.Date > to) {
+			continue
+		}
+		result = append(result, entry)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		return a.Model < b.Model
+	})
+	return result, nil
+}
+
+// load reads all entries; the caller holds the mutex
+func (s *Store) load() ([]Entry, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage: %w", err)
+	}
+
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse usage %s: %w", s.path, err)
+	}
+	return entries, nil
+}
+
+// save writes all entries; the caller holds the mutex
+func (s *Store) save(entries []Entry) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create usage directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode usage: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write usage: %w", err)
+	}
+	return nil
+}
+
+// Total sums the entries; the date, provider and model of the result are empty
+func Total(entries []Entry) Entry {
+	var total Entry
+	for _, entry := range entries {
+		total.add(entry)
+	}
+	return total
+}
+
+// TotalCost estimates the cost of the entries
+// Returns false if any of them has no price
+func TotalCost(entries []Entry, prices map[string]config.PriceConfig) (float64, bool) {
+	var total float64
+	for _, entry := range entries {
+		cost, ok := entry.Cost(prices)
+		if !ok {
+			return total, false
+		}
+		total += cost
+	}
+	return total, true
+}
+
+// Summary formats the totals of the entries in one line, e.g. for the tray menu:
+// "3 requests, 1.5 min of audio, 420 tokens, ~0.0020"
+func Summary(entries []Entry, prices map[string]config.PriceConfig) string {
+	total := Total(entries)
+	parts := []string{
+		fmt.Sprintf("%d requests", total.Requests),
+		fmt.Sprintf("%.1f min of audio", total.AudioSeconds/60),
+	}
+	if total.TotalTokens > 0 {
+		parts = append(parts, fmt.Sprintf("%d tokens", total.TotalTokens))
+	}
+	if cost, ok := TotalCost(entries, prices); ok && len(entries) > 0 {
+		parts = append(parts, fmt.Sprintf("~%.4f", cost))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Report writes the entries as a table followed by their total
+// The cost column shows "-" for models without a price
+func Report(w io.Writer, entries []Entry, prices map[string]config.PriceConfig) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "DATE\tPROVIDER\tMODEL\tREQUESTS\tAUDIO (MIN)\tINPUT TOKENS\tOUTPUT TOKENS\tCOST")
+
+	row := func(entry Entry, cost float64, priced bool) {
+		costText := "-"
+		if priced {
+			costText = fmt.Sprintf("%.4f", cost)
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%d\t%.1f\t%d\t%d\t%s\n",
+			entry.Date, entry.Provider, entry.Model, entry.Requests, entry.AudioSeconds/60,
+			entry.PromptTokens, entry.CompletionTokens, costText)
+	}
+
+	for _, entry := range entries {
+		cost, priced := entry.Cost(prices)
+		row(entry, cost, priced)
+	}
+
+	total := Total(entries)
+	total.Date = "TOTAL"
+	cost, priced := TotalCost(entries, prices)
+	row(total, cost, priced)
+
+	return table.Flush()
+}
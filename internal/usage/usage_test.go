@@ -0,0 +1,109 @@
+package usage
+
+import (
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/d-mozulyov/vox/pkg/config"
+)
+
+// testPrices prices the chat model by tokens and the whisper model by audio
+var testPrices = map[string]config.PriceConfig{
+	"voxtral": {PerMillionInputTokens: 40, PerMillionOutputTokens: 100},
+	"whisper": {PerAudioMinute: 0.006},
+}
+
+// TestRecord tests that requests are summed per day, provider and model
+func TestRecord(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "usage.json"))
+
+	day1 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.Local)
+	day2 := day1.Add(24 * time.Hour)
+	records := []struct {
+		at    time.Time
+		entry Entry
+	}{
+		{day1, Entry{Provider: "mistral", Model: "voxtral", Requests: 1, AudioSeconds: 30, PromptTokens: 1000, CompletionTokens: 50, TotalTokens: 1050}},
+		{day1, Entry{Provider: "mistral", Model: "voxtral", Requests: 1, AudioSeconds: 30, PromptTokens: 1000, CompletionTokens: 50, TotalTokens: 1050}},
+		{day1, Entry{Provider: "openai", Model: "whisper", Requests: 1, AudioSeconds: 120}},
+		{day2, Entry{Provider: "mistral", Model: "voxtral", Requests: 1, AudioSeconds: 6}},
+	}
+	for _, r := range records {
+		if err := store.Record(r.at, r.entry); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+
+	entries, err := store.Entries("", "")
+	if err != nil {
+		t.Fatalf("Entries failed: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %+v", entries)
+	}
+	first := entries[0]
+	if first.Date != "2026-03-01" || first.Provider != "mistral" || first.Requests != 2 || first.AudioSeconds != 60 || first.TotalTokens != 2100 {
+		t.Errorf("Unexpected first entry: %+v", first)
+	}
+
+	// Date filters are inclusive
+	entries, err = store.Entries("2026-03-02", "2026-03-02")
+	if err != nil || len(entries) != 1 || entries[0].Date != "2026-03-02" {
+		t.Errorf("Unexpected filtered entries: %+v, %v", entries, err)
+	}
+	entries, _ = store.Entries("", "2026-03-01")
+	if len(entries) != 2 {
+		t.Errorf("Expected 2 entries up to 2026-03-01, got %d", len(entries))
+	}
+}
+
+// TestCost tests cost estimation by tokens and by audio minutes
+func TestCost(t *testing.T) {
+	tokens := Entry{Model: "voxtral", PromptTokens: 2000, CompletionTokens: 100}
+	if cost, ok := tokens.Cost(testPrices); !ok || math.Abs(cost-0.09) > 1e-9 {
+		t.Errorf("Expected token cost 0.09, got %v (%v)", cost, ok)
+	}
+
+	audio := Entry{Model: "whisper", AudioSeconds: 120}
+	if cost, ok := audio.Cost(testPrices); !ok || math.Abs(cost-0.012) > 1e-9 {
+		t.Errorf("Expected audio cost 0.012, got %v (%v)", cost, ok)
+	}
+
+	if _, ok := (Entry{Model: "unknown"}).Cost(testPrices); ok {
+		t.Error("Expected no cost for a model without a price")
+	}
+	if _, ok := TotalCost([]Entry{tokens, {Model: "unknown"}}, testPrices); ok {
+		t.Error("Expected no total cost when a model has no price")
+	}
+}
+
+// TestReport tests the table written by the usage command
+func TestReport(t *testing.T) {
+	entries := []Entry{
+		{Date: "2026-03-01", Provider: "openai", Model: "whisper", Requests: 2, AudioSeconds: 120},
+		{Date: "2026-03-01", Provider: "local", Requests: 1, AudioSeconds: 30},
+	}
+
+	var out strings.Builder
+	if err := Report(&out, entries, testPrices); err != nil {
+		t.Fatalf("Report failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected header, 2 rows and total, got:\n%s", out.String())
+	}
+	if !strings.Contains(lines[1], "0.0120") || !strings.HasSuffix(lines[2], "-") {
+		t.Errorf("Unexpected cost columns:\n%s", out.String())
+	}
+	if fields := strings.Fields(lines[3]); fields[0] != "TOTAL" || fields[1] != "3" {
+		t.Errorf("Unexpected total row: %q", lines[3])
+	}
+
+	if summary := Summary(entries[:1], testPrices); summary != "2 requests, 2.0 min of audio, ~0.0120" {
+		t.Errorf("Unexpected summary: %q", summary)
+	}
+}
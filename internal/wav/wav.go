@@ -0,0 +1,132 @@
+// Package wav encodes and decodes 16-bit PCM WAV files.
+// It has no cgo dependencies, so the packages that store or upload recordings
+// do not link the capture library.
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// channels is the channel count of encoded files (mono)
+const channels = 1
+
+// Encode encodes 16-bit mono PCM samples as a WAV file
+func Encode(samples []int16, sampleRate int) []byte {
+	dataSize := uint32(len(samples) * 2)
+
+	var buf bytes.Buffer
+	buf.Grow(44 + int(dataSize))
+
+	// RIFF header
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, 36+dataSize)
+	buf.WriteString("WAVE")
+
+	// fmt chunk: PCM, mono, 16-bit
+	buf.WriteString("fmt ")
+	binary.Write(&buf, binary.LittleEndian, uint32(16))
+	binary.Write(&buf, binary.LittleEndian, uint16(1))
+	binary.Write(&buf, binary.LittleEndian, uint16(channels))
+	binary.Write(&buf, binary.LittleEndian, uint32(sampleRate))
+	binary.Write(&buf, binary.LittleEndian, uint32(sampleRate*channels*2))
+	binary.Write(&buf, binary.LittleEndian, uint16(channels*2))
+	binary.Write(&buf, binary.LittleEndian, uint16(16))
+
+	// data chunk
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, dataSize)
+	binary.Write(&buf, binary.LittleEndian, samples)
+
+	return buf.Bytes()
+}
+
+// Format describes the samples of a WAV file
+type Format struct {
+	SampleRate int
+	Channels   int
+}
+
+// Decode decodes a 16-bit mono PCM WAV file, such as one written by Encode
+// Returns the samples and the sample rate
+func Decode(data []byte) ([]int16, int, error) {
+	samples, format, err := Read(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	if format.Channels != 1 {
+		return nil, 0, fmt.Errorf("unsupported WAV format: %d channels, mono expected", format.Channels)
+	}
+	return samples, format.SampleRate, nil
+}
+
+// Read decodes a 16-bit PCM WAV file with any channel count and sample rate
+// Returns the samples, interleaved if there are several channels
+// A file cut short (e.g. by a crash while it was written) keeps the samples it has
+func Read(data []byte) ([]int16, Format, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, Format{}, errors.New("not a WAV file")
+	}
+
+	var format Format
+	var bits int
+	var pcm []byte
+	for chunks := data[12:]; len(chunks) >= 8; {
+		id := string(chunks[0:4])
+		size := int(binary.LittleEndian.Uint32(chunks[4:8]))
+		body := chunks[8:]
+		if size > len(body) {
+			size = len(body)
+		}
+		body = body[:size]
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, Format{}, errors.New("invalid fmt chunk")
+			}
+			// 1 is PCM, 0xFFFE is WAVE_FORMAT_EXTENSIBLE, used by some editors for PCM too
+			if encoding := binary.LittleEndian.Uint16(body[0:2]); encoding != 1 && encoding != 0xFFFE {
+				return nil, Format{}, fmt.Errorf("unsupported WAV encoding %d, only PCM is supported", encoding)
+			}
+			format.Channels = int(binary.LittleEndian.Uint16(body[2:4]))
+			format.SampleRate = int(binary.LittleEndian.Uint32(body[4:8]))
+			bits = int(binary.LittleEndian.Uint16(body[14:16]))
+		case "data":
+			pcm = body
+		}
+
+		// Chunks are padded to an even size
+		next := 8 + size + size%2
+		if next > len(chunks) {
+			break
+		}
+		chunks = chunks[next:]
+	}
+
+	switch {
+	case format.SampleRate == 0:
+		return nil, Format{}, errors.New("WAV file has no fmt chunk")
+	case pcm == nil:
+		return nil, Format{}, errors.New("WAV file has no data chunk")
+	case bits != 16:
+		return nil, Format{}, fmt.Errorf("unsupported WAV sample size %d bits, only 16 is supported", bits)
+	case format.Channels == 0:
+		return nil, Format{}, errors.New("WAV file has no channels")
+	}
+
+	// A partial frame at the end of a file cut short is dropped
+	samples := Samples(pcm)
+	return samples[:len(samples)/format.Channels*format.Channels], format, nil
+}
+
+// Samples converts little-endian 16-bit PCM bytes to samples
+func Samples(data []byte) []int16 {
+	samples := make([]int16, len(data)/2)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(data[i*2:]))
+	}
+	return samples
+}
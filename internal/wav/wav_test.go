@@ -0,0 +1,95 @@
+package wav
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// sampleRate is the rate of the test files (the recorder's)
+const sampleRate = 16000
+
+// TestSamples tests little-endian PCM conversion
+func TestSamples(t *testing.T) {
+	samples := Samples([]byte{0x01, 0x00, 0xFF, 0xFF, 0x00, 0x80})
+	expected := []int16{1, -1, -32768}
+	if len(samples) != len(expected) {
+		t.Fatalf("Expected %d samples, got %d", len(expected), len(samples))
+	}
+	for i := range expected {
+		if samples[i] != expected[i] {
+			t.Errorf("Sample %d: expected %d, got %d", i, expected[i], samples[i])
+		}
+	}
+}
+
+// TestEncode tests the WAV header and sample layout
+func TestEncode(t *testing.T) {
+	data := Encode([]int16{1, -1}, sampleRate)
+
+	if len(data) != 48 {
+		t.Fatalf("Expected 48 bytes, got %d", len(data))
+	}
+	if string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" || string(data[36:40]) != "data" {
+		t.Errorf("Invalid WAV chunk IDs: %q", data[:40])
+	}
+	if rate := binary.LittleEndian.Uint32(data[24:28]); rate != sampleRate {
+		t.Errorf("Expected sample rate %d, got %d", sampleRate, rate)
+	}
+	if samples := Samples(data[44:]); samples[0] != 1 || samples[1] != -1 {
+		t.Errorf("Unexpected samples: %v", samples)
+	}
+}
+
+// TestDecode tests that Decode reads what Encode writes
+func TestDecode(t *testing.T) {
+	samples, rate, err := Decode(Encode([]int16{1, -1, 32767}, sampleRate))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if rate != sampleRate || len(samples) != 3 || samples[0] != 1 || samples[1] != -1 || samples[2] != 32767 {
+		t.Errorf("Unexpected result: %v at %d Hz", samples, rate)
+	}
+
+	if _, _, err := Decode([]byte("not a wav file")); err == nil {
+		t.Error("Expected error for invalid data")
+	}
+}
+
+// TestRead tests files with several channels, other encodings and files cut short
+func TestRead(t *testing.T) {
+	stereo := Encode([]int16{1, -1, 2, -2}, sampleRate)
+	binary.LittleEndian.PutUint16(stereo[22:], 2)
+	samples, format, err := Read(stereo)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if format != (Format{SampleRate: sampleRate, Channels: 2}) || len(samples) != 4 || samples[3] != -2 {
+		t.Errorf("Unexpected result: %v, %+v", samples, format)
+	}
+	if _, _, err := Decode(stereo); err == nil {
+		t.Error("Expected Decode to reject stereo")
+	}
+
+	// A file cut short keeps the samples it has, without a partial frame
+	if samples, _, err := Read(stereo[:len(stereo)-3]); err != nil || len(samples) != 2 {
+		t.Errorf("Expected the first frame of a truncated file, got %v, %v", samples, err)
+	}
+
+	extensible := Encode([]int16{7}, sampleRate)
+	binary.LittleEndian.PutUint16(extensible[20:], 0xFFFE)
+	if samples, _, err := Decode(extensible); err != nil || len(samples) != 1 || samples[0] != 7 {
+		t.Errorf("Expected WAVE_FORMAT_EXTENSIBLE to be read as PCM, got %v, %v", samples, err)
+	}
+
+	eightBit := Encode([]int16{0}, sampleRate)
+	binary.LittleEndian.PutUint16(eightBit[34:], 8)
+	for name, data := range map[string][]byte{
+		"8-bit":   eightBit,
+		"no data": Encode(nil, sampleRate)[:36],
+		"no fmt":  append([]byte("RIFF\x00\x00\x00\x00WAVE"), Encode([]int16{1}, sampleRate)[36:]...),
+	} {
+		if _, _, err := Read(data); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -1,15 +1,26 @@
 package config
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 )
 
 // Config holds application configuration
 type Config struct {
-	Hotkey  HotkeyConfig
-	Audio   AudioConfig
-	Logging LoggingConfig
+	Hotkey        HotkeyConfig
+	Audio         AudioConfig
+	Assets        AssetsConfig
+	Notifications NotificationsConfig
+	Recordings    RecordingsConfig
+	Queue         QueueConfig
+	Recovery      RecoveryConfig
+	Transcription TranscriptionConfig
+	Timeouts      TimeoutsConfig
+	Usage         UsageConfig
+	Logging       LoggingConfig
 }
 
 // HotkeyConfig holds hotkey configuration
@@ -20,12 +31,201 @@ type HotkeyConfig struct {
 	UseShift bool
 	UseCtrl  bool
 	Key      string
+
+	// Cancel discards the recording or stops the transcription
+	Cancel CancelHotkeyConfig
+}
+
+// CancelHotkeyConfig holds the cancel hotkey configuration
+type CancelHotkeyConfig struct {
+	Enabled bool
+	// Default: Alt+Shift+X
+	UseAlt   bool
+	UseShift bool
+	UseCtrl  bool
+	Key      string // A to Z
 }
 
 // AudioConfig holds audio configuration
 type AudioConfig struct {
+	Enabled bool    // feedback sounds
+	Volume  float64 // 0.0 to 1.0, multiplied by the gains of the sound theme
+
+	// Theme is the sound theme: "default" for the built-in sounds, or the name
+	// of a subdirectory of ThemesDir holding a theme.json manifest
+	Theme     string
+	ThemesDir string
+
+	// PlaybackPolicy decides what a new sound does to one still playing:
+	// "interrupt" (default) stops it, "overlap" plays on top of it
+	PlaybackPolicy string
+
+	// PreRollEnabled keeps the microphone open while idle so the first word
+	// is not clipped. The last PreRollMs of audio are held in memory only,
+	// never written to disk, and prepended to the next recording.
+	// Disabled by default for privacy.
+	PreRollEnabled bool
+	PreRollMs      int // 300 to 800 recommended
+
+	// Filters applied to captured audio, in this order
+	HighPass  HighPassConfig
+	NoiseGate NoiseGateConfig
+	AGC       AGCConfig
+	Limiter   LimiterConfig
+}
+
+// AssetsConfig holds configuration of icons and sounds
+// The defaults are built into the binary; a file in Dir at the same relative path
+// replaces one of them, e.g. icons/idle_24.png or sounds/cancelled.wav.
+// `vox assets export` writes the defaults to Dir.
+type AssetsConfig struct {
+	Dir string
+}
+
+// NotificationsConfig holds configuration of desktop notifications
+// Failures and transcripts of queued or recovered recordings are notified
+// through org.freedesktop.Notifications where a notification server is running.
+// Preview also notifies every transcript, with Copy and Retry buttons; it is off
+// by default because notifications may be shown on the lock screen or logged.
+type NotificationsConfig struct {
+	Enabled bool
+	Preview bool
+}
+
+// HighPassConfig holds high-pass filter (rumble removal) configuration
+type HighPassConfig struct {
+	Enabled  bool
+	CutoffHz float64
+}
+
+// NoiseGateConfig holds noise gate configuration
+type NoiseGateConfig struct {
+	Enabled     bool
+	ThresholdDB float64 // gate opens above this level, dBFS
+	HoldMs      int     // time the gate stays open after the level drops
+}
+
+// AGCConfig holds automatic gain control configuration
+type AGCConfig struct {
+	Enabled   bool
+	TargetDB  float64 // target RMS level, dBFS
+	MaxGainDB float64 // maximum amplification
+}
+
+// LimiterConfig holds clipping limiter configuration
+type LimiterConfig struct {
+	Enabled   bool
+	CeilingDB float64 // peak ceiling, dBFS
+}
+
+// RecordingsConfig holds configuration for saving recordings (debugging and replay)
+// Each recording is saved as WAV with a JSON sidecar (context, backend, transcript, latency)
+type RecordingsConfig struct {
+	Enabled    bool
+	Incognito  bool // overrides Enabled: nothing is saved, queued or journaled
+	Dir        string
+	MaxSizeMB  int // total size limit, 0 means unlimited
+	MaxAgeDays int // age limit, 0 means unlimited
+}
+
+// QueueConfig holds configuration of the offline queue
+// Recordings whose transcription fails with a temporary error (offline,
+// rate limit, server error, timeout) are kept in Dir and retried in the background.
+// Nothing is queued in incognito mode.
+type QueueConfig struct {
+	Enabled bool
+	Dir     string
+}
+
+// RecoveryConfig holds configuration of crash recovery
+// The audio of the active session is journaled in Dir while it is recorded and
+// transcribed, so an interrupted session can be recovered on the next start.
+// Nothing is journaled in incognito mode.
+type RecoveryConfig struct {
+	Enabled bool
+	Dir     string
+}
+
+// TranscriptionConfig holds speech-to-text configuration
+type TranscriptionConfig struct {
+	Provider ProviderConfig
+	// Fallbacks are tried in order when a provider fails with a timeout,
+	// rate limit (429), server (5xx) or network error
+	Fallbacks []ProviderConfig
+
+	Prompt         string   // system prompt sent along with the audio (chat backend)
+	Language       string   // ISO-639-1 code, empty for auto-detection
+	Glossary       []string // names and terms the model should spell correctly
+	Stream         bool     // stream the response and insert text as it arrives (chat backend)
+	TimeoutSeconds int      // overall deadline of the Transcribing state, including retries and fallbacks
+}
+
+// ProviderConfig holds the connection to a speech-to-text provider
+// Empty fields are filled from the preset, see Presets
+type ProviderConfig struct {
+	Preset         string // mistral, openai, groq, local or empty for a custom provider
+	Backend        string // BackendChat, BackendWhisper, BackendInference or BackendCommand
+	BaseURL        string // API base URL, e.g. https://api.mistral.ai/v1
+	APIKey         string
+	Model          string
+	ResponseFormat string // multipart backends: json, verbose_json or text
+
+	// Local marks a provider running on this machine: audio never leaves it,
+	// so Vox never falls back from it to a cloud provider
+	Local bool
+
+	Retry RetryConfig
+
+	// Multipart backends (whisper, inference) of self-hosted servers
+	Path      string            // request path, e.g. /inference
+	Fields    map[string]string // renamed form fields: file, model, language, prompt, response_format ("" omits all but file)
+	TextField string            // dot-separated path of the transcript in a JSON response, default "text"
+
+	// Command backend: the recording is piped to the command's stdin
+	// and the transcript is read from its stdout
+	Command     []string // executable and arguments
+	AudioFormat string   // wav (default) or pcm (raw 16-bit little-endian mono)
+	ContextMode string   // env (default): VOX_* variables; file: JSON file in VOX_CONTEXT_FILE
+}
+
+// RetryConfig holds the retry policy of a provider
+// Temporary failures (timeout, 429, 5xx, network error) are retried with
+// jittered exponential backoff; Retry-After of 429 and 503 responses is respected.
+// Zero values use the defaults: 3 attempts, 500 ms initial delay,
+// 5 s maximum delay and 30 s per attempt.
+type RetryConfig struct {
+	MaxAttempts           int // 1 disables retries
+	InitialDelayMs        int
+	MaxDelayMs            int
+	AttemptTimeoutSeconds int
+}
+
+// TimeoutsConfig holds the limits of the application states, 0 disables a limit
+type TimeoutsConfig struct {
+	// MaxRecordingSeconds stops a recording and transcribes it
+	MaxRecordingSeconds int
+	// MaxTranscribingSeconds cancels a transcription and shows the error state.
+	// Keep it above Transcription.TimeoutSeconds, which ends a transcription
+	// with a retryable error so that its recording is queued.
+	MaxTranscribingSeconds int
+	// ErrorDisplaySeconds is how long the error state is shown before returning to idle
+	ErrorDisplaySeconds int
+}
+
+// UsageConfig holds usage accounting configuration
+// Requests, audio seconds and tokens are summed per day, provider and model in Path
+type UsageConfig struct {
 	Enabled bool
-	Volume  float64 // 0.0 to 1.0
+	Path    string
+	// Prices by model name (e.g. "voxtral-mini-latest") to estimate the cost
+	Prices map[string]PriceConfig
+}
+
+// PriceConfig holds the prices of a model, in the currency of the provider's price list
+type PriceConfig struct {
+	PerAudioMinute         float64
+	PerMillionInputTokens  float64
+	PerMillionOutputTokens float64
 }
 
 // LoggingConfig holds logging configuration
@@ -46,10 +246,79 @@ func Default() *Config {
 			UseShift: true,
 			UseCtrl:  false,
 			Key:      "V",
+			Cancel: CancelHotkeyConfig{
+				Enabled:  true,
+				UseAlt:   true,
+				UseShift: true,
+				UseCtrl:  false,
+				Key:      "X",
+			},
 		},
 		Audio: AudioConfig{
+			Enabled:        true,
+			Volume:         0.8,
+			Theme:          "default",
+			ThemesDir:      filepath.Join(homeDir, ".vox", "themes"),
+			PlaybackPolicy: "interrupt",
+			PreRollEnabled: false,
+			PreRollMs:      500,
+			HighPass: HighPassConfig{
+				Enabled:  true,
+				CutoffHz: 80,
+			},
+			NoiseGate: NoiseGateConfig{
+				Enabled:     false,
+				ThresholdDB: -50,
+				HoldMs:      250,
+			},
+			AGC: AGCConfig{
+				Enabled:   false,
+				TargetDB:  -20,
+				MaxGainDB: 20,
+			},
+			Limiter: LimiterConfig{
+				Enabled:   true,
+				CeilingDB: -1,
+			},
+		},
+		Assets: AssetsConfig{
+			Dir: filepath.Join(homeDir, ".vox", "assets"),
+		},
+		Notifications: NotificationsConfig{
+			Enabled: true,
+			Preview: false,
+		},
+		Recordings: RecordingsConfig{
+			Enabled:    false,
+			Incognito:  false,
+			Dir:        filepath.Join(homeDir, ".vox", "recordings"),
+			MaxSizeMB:  200,
+			MaxAgeDays: 7,
+		},
+		Queue: QueueConfig{
+			Enabled: true,
+			Dir:     filepath.Join(homeDir, ".vox", "queue"),
+		},
+		Recovery: RecoveryConfig{
+			Enabled: true,
+			Dir:     filepath.Join(homeDir, ".vox", "journal"),
+		},
+		Transcription: TranscriptionConfig{
+			Provider: ProviderConfig{
+				Preset: "mistral",
+			},
+			Prompt:         "Transcribe the audio exactly as spoken. Reply with the transcript only, without any comments.",
+			Stream:         true,
+			TimeoutSeconds: 60,
+		},
+		Timeouts: TimeoutsConfig{
+			MaxRecordingSeconds:    300,
+			MaxTranscribingSeconds: 90,
+			ErrorDisplaySeconds:    3,
+		},
+		Usage: UsageConfig{
 			Enabled: true,
-			Volume:  0.8,
+			Path:    filepath.Join(homeDir, ".vox", "usage.json"),
 		},
 		Logging: LoggingConfig{
 			Level:    "info",
@@ -57,3 +326,52 @@ func Default() *Config {
 		},
 	}
 }
+
+// Path returns the default configuration file path (~/.vox/config.json)
+func Path() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".vox", "config.json")
+}
+
+// Load reads the configuration from a JSON file
+// Missing file or missing fields fall back to default values
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// Save writes the configuration to a JSON file, creating the directory if needed
+// The file holds API keys, so it is readable by the owner only
+func (c *Config) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	// WriteFile keeps the mode of an existing file, e.g. one written by an older version
+	if err := os.Chmod(path, 0600); err != nil {
+		return fmt.Errorf("failed to restrict config permissions: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// TestLoadMissingFile tests that a missing config file yields defaults
+func TestLoadMissingFile(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "config.json"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Audio.PreRollEnabled {
+		t.Error("Expected pre-roll to be disabled by default")
+	}
+	if cfg.Audio.Volume != 0.8 {
+		t.Errorf("Expected default volume 0.8, got %f", cfg.Audio.Volume)
+	}
+}
+
+// TestSaveLoad tests that saved values survive a round trip and missing fields keep defaults
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vox", "config.json")
+
+	cfg := Default()
+	cfg.Audio.PreRollEnabled = true
+	if err := cfg.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !loaded.Audio.PreRollEnabled {
+		t.Error("Expected pre-roll to be enabled after reload")
+	}
+
+	// Partial file: unspecified fields keep their defaults
+	if err := os.WriteFile(path, []byte(`{"Audio": {"Volume": 0.5}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.Audio.Volume != 0.5 || loaded.Audio.PreRollMs != 500 || !loaded.Hotkey.Enabled {
+		t.Errorf("Unexpected partial config result: %+v", loaded)
+	}
+}
+
+// TestResolveProvider tests that presets fill only the fields that are not set
+func TestResolveProvider(t *testing.T) {
+	provider, err := ProviderConfig{Preset: "openai", Model: "gpt-4o-transcribe"}.Resolve()
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if provider.Backend != BackendWhisper || provider.BaseURL != "https://api.openai.com/v1" {
+		t.Errorf("Preset fields not applied: %+v", provider)
+	}
+	if provider.Model != "gpt-4o-transcribe" {
+		t.Errorf("Expected model override to be kept, got %s", provider.Model)
+	}
+
+	if _, err := (ProviderConfig{Preset: "unknown"}).Resolve(); err == nil {
+		t.Error("Expected error for unknown preset")
+	}
+
+	local, err := ProviderConfig{Preset: "local", BaseURL: "http://192.168.1.10:8080"}.Resolve()
+	if err != nil || !local.Local || local.Backend != BackendInference {
+		t.Errorf("Expected local inference provider, got %+v, %v", local, err)
+	}
+
+	custom, err := ProviderConfig{BaseURL: "http://localhost:8080/v1"}.Resolve()
+	if err != nil || custom.Backend != BackendChat {
+		t.Errorf("Expected custom provider with chat backend, got %+v, %v", custom, err)
+	}
+}
+
+// TestSavePermissions tests that the config, which holds API keys, is readable by the owner only
+func TestSavePermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix permissions")
+	}
+	path := filepath.Join(t.TempDir(), "vox", "config.json")
+
+	// A file written by an older version is tightened too
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := Default()
+	cfg.Transcription.Provider.APIKey = "secret"
+	if err := cfg.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("Expected mode 0600, got %o", mode)
+	}
+}
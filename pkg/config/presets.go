@@ -0,0 +1,81 @@
+package config
+
+import "fmt"
+
+// Transcription backends
+const (
+	// BackendChat sends audio to /chat/completions (context-aware transcription)
+	BackendChat = "chat"
+	// BackendWhisper uploads audio to /audio/transcriptions (classic transcription API)
+	BackendWhisper = "whisper"
+	// BackendInference uploads audio to a self-hosted server such as whisper.cpp (/inference)
+	BackendInference = "inference"
+	// BackendCommand pipes audio to a user-configured executable
+	BackendCommand = "command"
+)
+
+// Presets holds the known providers
+var Presets = map[string]ProviderConfig{
+	"mistral": {
+		Backend: BackendChat,
+		BaseURL: "https://api.mistral.ai/v1",
+		Model:   "voxtral-mini-latest",
+	},
+	"openai": {
+		Backend:        BackendWhisper,
+		BaseURL:        "https://api.openai.com/v1",
+		Model:          "whisper-1",
+		ResponseFormat: "json",
+	},
+	"groq": {
+		Backend:        BackendWhisper,
+		BaseURL:        "https://api.groq.com/openai/v1",
+		Model:          "whisper-large-v3-turbo",
+		ResponseFormat: "json",
+	},
+	// whisper.cpp server (examples/server) on its default port
+	"local": {
+		Backend:        BackendInference,
+		BaseURL:        "http://127.0.0.1:8080",
+		ResponseFormat: "json",
+		Local:          true,
+	},
+}
+
+// Resolve returns the provider with empty fields filled from its preset
+// A provider without a preset is returned as is, with the chat backend by default
+func (p ProviderConfig) Resolve() (ProviderConfig, error) {
+	if p.Preset != "" {
+		preset, ok := Presets[p.Preset]
+		if !ok {
+			return p, fmt.Errorf("unknown provider preset: %s", p.Preset)
+		}
+		if p.Backend == "" {
+			p.Backend = preset.Backend
+		}
+		if p.BaseURL == "" {
+			p.BaseURL = preset.BaseURL
+		}
+		if p.Model == "" {
+			p.Model = preset.Model
+		}
+		if p.ResponseFormat == "" {
+			p.ResponseFormat = preset.ResponseFormat
+		}
+		// A local preset stays local whatever the config says
+		p.Local = p.Local || preset.Local
+	}
+
+	if p.Backend == "" {
+		p.Backend = BackendChat
+	}
+	if p.Backend == BackendCommand {
+		if len(p.Command) == 0 {
+			return p, fmt.Errorf("command provider has no command")
+		}
+	} else if p.BaseURL == "" {
+		return p, fmt.Errorf("provider has no base URL")
+	}
+
+	return p, nil
+}